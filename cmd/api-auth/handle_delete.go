@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// revokeRole may revoke sessions of any user, users revoke only their own sessions.
+const revokeRole = auth.Manager
+
+// handleDelete revokes the session, used for logout, the record is kept until its TTL for audit.
+func handleDelete(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams, pathParams api.PathParams) (any, *api.HandleError) {
+	tokenID, err := pathParams.GetString("token_id")
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err = validate.ValidateField(tokenID, "len=32,hexadecimal"); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err, Message: "invalid token id"}
+	}
+	session, err := getSession(ctx, tokenID)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	meta := api.MustGetMetaData(ctx)
+	ownSession := session != nil && meta.GetIdentifier() == strconv.Itoa(session.UserId)
+	if !ownSession && !meta.HasPermissions(revokeRole) {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions"), Message: "insufficient permissions"}
+	}
+	if session == nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("session not found"), Message: "token not found"}
+	}
+
+	if err = revokeSession(ctx, tokenID, meta.GetIdentifier()); err != nil {
+		if cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusNotFound, Err: err, Message: "token not found"}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	logger.Info().
+		Str("audit", "auth").
+		Str("action", "revoke").
+		Str("token_id", tokenID).
+		Int("user_id", session.UserId).
+		Str("actor", meta.GetIdentifier()).
+		Str("role", auth.RoleNames[meta.GetRole()]).
+		Msg("Session revoked")
+
+	return nil, nil
+}
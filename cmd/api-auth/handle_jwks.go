@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/rs/zerolog"
+)
+
+// jwksCacheControl lets verifiers cache keys, rotation keeps old keys published longer than this.
+const jwksCacheControl = "public, max-age=300"
+
+// handleGetJWKS publishes public keys which verify access tokens, the route is public.
+func handleGetJWKS(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	keys, err := jwtAuth.PublicKeys(ctx)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return api.NewResponse(keys).WithCacheControl(jwksCacheControl), nil
+}
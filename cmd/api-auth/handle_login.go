@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingousertoken"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+// unknownUserHash is checked for unknown usernames, so they take as long as wrong passwords.
+const unknownUserHash = "$2a$10$rHM.xKQZQ58xrb9BD1qQT.M.z6hFVZVBrmSi8OFS0Blzn3I5V1Yui"
+
+// handleLogin checks user credentials and starts a session with access and refresh tokens.
+func handleLogin(ctx context.Context, logger zerolog.Logger, req applingoapi.RequestPostAuthLoginV1, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	user, err := getUser(ctx, req.Username)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	passwordHash := unknownUserHash
+	if user != nil {
+		passwordHash = user.PasswordHash
+	}
+	if err = auth.CheckPassword(passwordHash, req.Password); err != nil || user == nil || user.DisabledAt != 0 {
+		logger.Warn().
+			Str("audit", "auth").
+			Str("action", "login_failed").
+			Str("username", req.Username).
+			Msg("Login failed")
+		return nil, &api.HandleError{Status: http.StatusUnauthorized, Err: auth.ErrInvalidCredentials, Message: auth.ErrInvalidCredentials.Error()}
+	}
+	role, ok := userRole(user)
+	if !ok {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: auth.ErrInvalidCredentials, Message: "user cannot log in"}
+	}
+
+	tokenID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	refresh, refreshHash, err := auth.NewRefreshToken(tokenID)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	now := time.Now()
+	item, err := applingousertoken.PutItem(applingousertoken.SchemaItem{
+		TokenId:     tokenID,
+		UserId:      user.Id,
+		Username:    user.Username,
+		Role:        int(role),
+		RefreshHash: refreshHash,
+		Created:     int(now.Unix()),
+		RotatedAt:   int(now.Unix()),
+		Ttl:         sessionExpiresAt(now),
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if err = dbDynamo.Put(ctx, applingousertoken.TableName, item, expression.AttributeNotExists(expression.Name("token_id"))); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	access, err := accessToken(user.Id, role, tokenID)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	logger.Info().
+		Str("audit", "auth").
+		Str("action", "login").
+		Str("username", user.Username).
+		Int("user_id", user.Id).
+		Str("token_id", tokenID).
+		Str("device", api.MustGetMetaData(ctx).GetIdentifier()).
+		Msg("User logged in")
+
+	return &api.Response{Status: http.StatusOK, Body: openapi.DataResponseAuth(tokensData(tokenID, access, refresh))}, nil
+}
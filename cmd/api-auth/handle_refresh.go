@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingousertoken"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+// reuseActor is recorded as revoked_by when a reused refresh token revokes its session.
+const reuseActor = "refresh_reuse"
+
+// handleRefresh exchanges the refresh token for a new pair. Refresh tokens are single-use:
+// a token the session already replaced means it leaked, so the whole session is revoked.
+// Other tokens are rejected only, a guessed secret with a known token ID cannot log the user out.
+func handleRefresh(ctx context.Context, logger zerolog.Logger, req applingoapi.RequestPostAuthRefreshV1, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	unauthorized := &api.HandleError{Status: http.StatusUnauthorized, Err: auth.ErrInvalidRefresh, Message: auth.ErrInvalidRefresh.Error()}
+
+	tokenID, secret, ok := auth.ParseRefreshToken(req.RefreshToken)
+	if !ok {
+		return nil, unauthorized
+	}
+	session, err := getSession(ctx, tokenID)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	now := time.Now()
+	if session == nil || session.RevokedAt != 0 || int64(session.Ttl) <= now.Unix() {
+		return nil, unauthorized
+	}
+	presentedHash := auth.RefreshTokenHash(secret)
+	if subtle.ConstantTimeCompare([]byte(presentedHash), []byte(session.RefreshHash)) != 1 {
+		if isPreviousHash(session.PreviousHashes, presentedHash) {
+			return nil, revokeReused(ctx, logger, session)
+		}
+		return nil, unauthorized
+	}
+
+	user, err := getUser(ctx, session.Username)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if user == nil || user.DisabledAt != 0 || user.Id != session.UserId {
+		return nil, unauthorized
+	}
+	// the role is read again, so role changes apply with the next refresh.
+	role, ok := userRole(user)
+	if !ok {
+		return nil, unauthorized
+	}
+
+	refresh, refreshHash, err := auth.NewRefreshToken(tokenID)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	update := expression.
+		Set(expression.Name("refresh_hash"), expression.Value(refreshHash)).
+		Set(expression.Name("previous_hashes"), expression.Value(rotatePreviousHashes(session.PreviousHashes, presentedHash))).
+		Set(expression.Name("role"), expression.Value(int(role))).
+		Set(expression.Name("rotated_at"), expression.Value(int(now.Unix()))).
+		Set(expression.Name("ttl"), expression.Value(sessionExpiresAt(now)))
+	// a concurrent refresh with the same token loses here and is handled as reuse.
+	condition := activeCondition().And(expression.Name("refresh_hash").Equal(expression.Value(presentedHash)))
+
+	if err = dbDynamo.Update(ctx, applingousertoken.TableName, sessionKey(tokenID), update, condition); err != nil {
+		if cloud.IsConditionFailed(err) {
+			return nil, revokeReused(ctx, logger, session)
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	access, err := accessToken(user.Id, role, tokenID)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return &api.Response{Status: http.StatusOK, Body: openapi.DataResponseAuth(tokensData(tokenID, access, refresh))}, nil
+}
+
+func revokeReused(ctx context.Context, logger zerolog.Logger, session *applingousertoken.SchemaItem) *api.HandleError {
+	if err := revokeSession(ctx, session.TokenId, reuseActor); err != nil && !cloud.IsConditionFailed(err) {
+		return &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	logger.Warn().
+		Str("audit", "auth").
+		Str("action", "refresh_reuse").
+		Str("token_id", session.TokenId).
+		Int("user_id", session.UserId).
+		Msg("Refresh token reused, session revoked")
+
+	return &api.HandleError{Status: http.StatusUnauthorized, Err: auth.ErrRefreshReused, Message: auth.ErrInvalidRefresh.Error()}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+const (
+	// accessTokenTTL keeps access tokens short-lived, revocation is also checked by the authorizer.
+	accessTokenTTL = 15 * time.Minute
+	// refreshTokenTTL is the session lifetime without refresh, every refresh extends it.
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	awsRegion       = os.Getenv("AWS_REGION")
+	jwtSecret       = os.Getenv("JWT_SECRET")
+	jwtSigningKey   = os.Getenv("JWT_SIGNING_KEY")
+	jwtSigningKeyID = os.Getenv("JWT_SIGNING_KEY_ID")
+	jwtJWKS         = os.Getenv("JWT_JWKS")
+
+	validate *validator.Validator
+	dbDynamo *cloud.Dynamo
+	jwtAuth  *auth.JWTAuth
+)
+
+func init() {
+	debug.SetGCPercent(500)
+	validate = validator.New()
+
+	if jwtSecret == "" && jwtSigningKey == "" {
+		panic("JWT_SECRET or JWT_SIGNING_KEY environment variable must be set")
+	}
+	jwtAuth = auth.NewJWTAuth(jwtSecret)
+	if jwtSigningKey != "" {
+		key, err := auth.ParseSigningKey(jwtSigningKeyID, []byte(jwtSigningKey))
+		if err != nil {
+			panic("invalid JWT_SIGNING_KEY: " + err.Error())
+		}
+		jwtAuth.WithSigningKey(key)
+	}
+	// published together with the signing key, keeps rotated keys verifiable until their tokens expire.
+	if jwtJWKS != "" {
+		keys, err := auth.NewKeySet(jwtJWKS, auth.DefaultJWKSCacheTTL)
+		if err != nil {
+			panic("invalid JWT_JWKS: " + err.Error())
+		}
+		jwtAuth.WithKeySet(keys)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+func main() {
+	api.NewLambda(
+		api.Config{
+			EnableRequestLogging: true,
+			CORS:                 api.DefaultCORSConfig(),
+			PublicRoutes:         []string{"GET /v1/auth/jwks"},
+		},
+		map[string]api.HandleFunc{
+			"GET /v1/auth/jwks":                 handleGetJWKS,
+			"POST /v1/auth/login":               api.Chain(api.WithBody(validate, handleLogin), api.RequireDevice()),
+			"POST /v1/auth/refresh":             api.Chain(api.WithBody(validate, handleRefresh), api.RequireDevice()),
+			"DELETE /v1/auth/tokens/{token_id}": api.Chain(handleDelete, api.RequireUser(auth.User)),
+		},
+		api.Timing(),
+	).Start()
+}
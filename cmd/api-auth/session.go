@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingouser"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingousertoken"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	tokenType = "Bearer"
+
+	// previousHashesLimit is how many replaced refresh hashes a session keeps to recognize reuse.
+	previousHashesLimit = 10
+	previousHashesSep   = ","
+)
+
+// getUser returns the user by username, nil when the user does not exist.
+func getUser(ctx context.Context, username string) (*applingouser.SchemaItem, error) {
+	result, err := dbDynamo.Get(ctx, applingouser.TableName, map[string]types.AttributeValue{
+		"username": &types.AttributeValueMemberS{Value: username},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get user")
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+	var user applingouser.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &user); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal user")
+	}
+	return &user, nil
+}
+
+// getSession returns the session by token ID, nil when the session does not exist.
+func getSession(ctx context.Context, tokenID string) (*applingousertoken.SchemaItem, error) {
+	result, err := dbDynamo.Get(ctx, applingousertoken.TableName, sessionKey(tokenID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get session")
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+	var session applingousertoken.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &session); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal session")
+	}
+	return &session, nil
+}
+
+// userRole returns the role the user may log in with, devices and guests never get a user session.
+func userRole(user *applingouser.SchemaItem) (auth.Role, bool) {
+	role := auth.Role(user.Role)
+	if !auth.UserRoleIsValid(role) {
+		return 0, false
+	}
+	return role, true
+}
+
+// revokeSession marks the session revoked, both its access and refresh tokens stop working.
+func revokeSession(ctx context.Context, tokenID, actor string) error {
+	update := expression.
+		Set(expression.Name("revoked_at"), expression.Value(int(time.Now().Unix()))).
+		Set(expression.Name("revoked_by"), expression.Value(actor))
+	return dbDynamo.Update(ctx, applingousertoken.TableName, sessionKey(tokenID), update, activeCondition())
+}
+
+// accessToken issues the access token of the session.
+func accessToken(userID int, role auth.Role, tokenID string) (string, error) {
+	token, err := jwtAuth.GenerateToken(userID, role, tokenID, accessTokenTTL)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign access token")
+	}
+	return token, nil
+}
+
+func tokensData(tokenID, access, refresh string) applingoapi.AuthTokensData {
+	return applingoapi.AuthTokensData{
+		TokenId:      tokenID,
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    tokenType,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}
+}
+
+// activeCondition matches existing sessions which are not revoked.
+func activeCondition() expression.ConditionBuilder {
+	return expression.AttributeExists(expression.Name("token_id")).
+		And(expression.Or(
+			expression.AttributeNotExists(expression.Name("revoked_at")),
+			expression.Name("revoked_at").Equal(expression.Value(0)),
+		))
+}
+
+func sessionKey(tokenID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"token_id": &types.AttributeValueMemberS{Value: tokenID},
+	}
+}
+
+// rotatePreviousHashes adds the replaced refresh hash to the previous hashes of the session, the oldest are dropped.
+func rotatePreviousHashes(previous, replaced string) string {
+	hashes := []string{replaced}
+	if previous != "" {
+		hashes = append(hashes, strings.Split(previous, previousHashesSep)...)
+	}
+	if len(hashes) > previousHashesLimit {
+		hashes = hashes[:previousHashesLimit]
+	}
+	return strings.Join(hashes, previousHashesSep)
+}
+
+// isPreviousHash reports whether the hash belongs to a refresh token the session already replaced.
+func isPreviousHash(previous, hash string) bool {
+	if previous == "" {
+		return false
+	}
+	for _, h := range strings.Split(previous, previousHashesSep) {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionExpiresAt is the DynamoDB TTL of the session refreshed at now.
+func sessionExpiresAt(now time.Time) int {
+	return int(now.Add(refreshTokenTTL).Unix())
+}
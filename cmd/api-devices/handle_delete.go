@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// revokeRole may revoke keys of any device, devices revoke only their own key.
+const revokeRole = auth.Manager
+
+// handleDelete revokes the device key, the record is kept for audit.
+func handleDelete(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams, pathParams api.PathParams) (any, *api.HandleError) {
+	keyID, err := pathParams.GetString("key_id")
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err = validate.ValidateField(keyID, "len=32,hexadecimal"); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err, Message: "invalid key id"}
+	}
+
+	meta := api.MustGetMetaData(ctx)
+	ownKey := meta.IsDevice() && meta.GetIdentifier() == keyID
+	if !ownKey && !(meta.IsUser() && meta.HasPermissions(revokeRole)) {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions"), Message: "insufficient permissions"}
+	}
+
+	update := expression.
+		Set(expression.Name("revoked_at"), expression.Value(int(time.Now().Unix()))).
+		Set(expression.Name("revoked_by"), expression.Value(meta.GetIdentifier()))
+	condition := expression.AttributeExists(expression.Name("key_id")).
+		And(expression.Or(
+			expression.AttributeNotExists(expression.Name("revoked_at")),
+			expression.Name("revoked_at").Equal(expression.Value(0)),
+		))
+
+	err = dbDynamo.Update(ctx, applingodevice.TableName, map[string]types.AttributeValue{
+		"key_id": &types.AttributeValueMemberS{Value: keyID},
+	}, update, condition)
+	if err != nil {
+		if cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusNotFound, Err: err, Message: "device key not found"}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	logger.Info().
+		Str("audit", "device").
+		Str("action", "revoke").
+		Str("key_id", keyID).
+		Str("actor", meta.GetIdentifier()).
+		Str("role", auth.RoleNames[meta.GetRole()]).
+		Msg("Device key revoked")
+
+	return nil, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+// handlePost registers a device and returns its credentials, the secret is stored encrypted with the KMS key.
+func handlePost(ctx context.Context, logger zerolog.Logger, req applingoapi.RequestPostDevicesV1, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	keyID, secret, err := auth.NewDeviceCredentials()
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	ciphertext, err := kmsKeys.Encrypt(ctx, secretKeyID, []byte(secret), auth.DeviceSecretContext(keyID))
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	item, err := applingodevice.PutItem(applingodevice.SchemaItem{
+		KeyId:            keyID,
+		SecretCiphertext: base64.StdEncoding.EncodeToString(ciphertext),
+		AppIdentifier:    req.AppIdentifier,
+		AppVersion:       req.AppVersion,
+		Created:          int(time.Now().Unix()),
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if err = dbDynamo.Put(ctx, applingodevice.TableName, item, expression.AttributeNotExists(expression.Name("key_id"))); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	logger.Info().
+		Str("audit", "device").
+		Str("action", "register").
+		Str("key_id", keyID).
+		Str("app_version", req.AppVersion).
+		Msg("Device registered")
+
+	return openapi.DataResponseDevices(applingoapi.DeviceCredentialsData{
+		KeyId:  keyID,
+		Secret: secret,
+	}), nil
+}
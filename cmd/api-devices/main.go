@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"os"
+	"runtime/debug"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+var (
+	awsRegion   = os.Getenv("AWS_REGION")
+	secretKeyID = os.Getenv("DEVICE_SECRET_KEY_ID")
+
+	validate *validator.Validator
+	dbDynamo *cloud.Dynamo
+	kmsKeys  *cloud.Kms
+)
+
+func init() {
+	debug.SetGCPercent(500)
+	validate = validator.New()
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+	kmsKeys = cloud.NewKms(cfg)
+}
+
+func main() {
+	api.NewLambda(
+		api.Config{
+			EnableRequestLogging: true,
+			CORS:                 api.DefaultCORSConfig(),
+		},
+		map[string]api.HandleFunc{
+			"POST /v1/devices":            api.Chain(api.WithBody(validate, handlePost), api.RequireDevice()),
+			"DELETE /v1/devices/{key_id}": api.Chain(handleDelete, api.RequireRole(auth.Device)),
+		},
+		api.Timing(),
+	).Start()
+}
@@ -4,49 +4,66 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
 
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
-func handleDelete(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
-	if api.MustGetMetaData(ctx).IsDevice() || !api.MustGetMetaData(ctx).HasPermissions(auth.User) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
-	params := applingoapi.DeleteDictionariesV1Params{
-		Name:        baseParams.GetStringDefault("name", ""),
-		Author:      baseParams.GetStringDefault("author", ""),
-		Subcategory: baseParams.GetStringDefault("subcategory", ""),
+func handleDelete(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.DeleteDictionariesV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
 	}
 	if err := validate.ValidateStruct(&params); err != nil {
 		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
 	}
 
-	id := generateDictionaryID(params.Name, params.Author)
-	result, err := dbDynamo.Get(ctx, applingodictionary.TableName, map[string]types.AttributeValue{
-		"id":          &types.AttributeValueMemberS{Value: id},
-		"subcategory": &types.AttributeValueMemberS{Value: params.Subcategory},
-	})
+	key := dictionaryKey(generateDictionaryID(params.Name, params.Author), params.Subcategory)
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableName, key)
 	if err != nil {
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get item for deletion")}
 	}
 	if result.Item == nil {
-		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found")}
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found"), Message: "item not found"}
+	}
+	var item applingodictionary.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if item.DeletedAt != 0 {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item is in trash"), Message: "item not found"}
+	}
+	if herr := authorizeMutation(ctx, logger, "delete", item); herr != nil {
+		return nil, herr
 	}
 
-	if err := dbDynamo.Delete(ctx, applingodictionary.TableName, map[string]types.AttributeValue{
-		"id":          &types.AttributeValueMemberS{Value: id},
-		"subcategory": &types.AttributeValueMemberS{Value: params.Subcategory},
-	}); err != nil {
+	// the dictionary goes to trash, DynamoDB TTL removes it after the retention period.
+	update := trash.
+		Delete(api.MustGetMetaData(ctx).GetIdentifier(), time.Now(), listingIndexKeys...).
+		Set(expression.Name("trashed_is_public"), expression.Value(item.IsPublic))
+	if err = dbDynamo.Update(ctx, applingodictionary.TableName, key, update, existingCondition()); err != nil {
+		if cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusNotFound, Err: err, Message: "item not found"}
+		}
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to delete item")}
 	}
 	return nil, nil
 }
+
+// listingIndexKeys are hash keys of the listing indexes, trashed dictionaries have none of them.
+var listingIndexKeys = []string{"is_public", "level#is_public", "subcategory#is_public", "level#subcategory#is_public"}
+
+// existingCondition matches dictionaries which exist and are not in trash.
+func existingCondition() expression.ConditionBuilder {
+	return expression.AttributeExists(expression.Name("id")).And(trash.NotDeleted())
+}
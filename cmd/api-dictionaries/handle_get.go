@@ -2,119 +2,161 @@ package main
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"net/http"
-	"sync"
+	"strconv"
 
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
-	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
-const pageLimit = 60
-
-func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).HasPermissions(auth.Device) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
+const (
+	defaultPageLimit = 60
+	// maxQueryRounds bounds DynamoDB queries per request when filtered items leave the page short.
+	maxQueryRounds = 5
+)
 
-	validSortValues := map[applingoapi.BaseDictSortEnum]struct{}{
-		applingoapi.Date:   {},
-		applingoapi.Rating: {},
-	}
-	paramSort, err := openapi.ParseEnumParam(baseParams.GetStringPtr("sort_by"), validSortValues)
-	if err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.Wrap(err, "invalid value for 'sort_by' param")}
-	}
-	params := applingoapi.GetDictionariesV1Params{
-		Subcategory:   baseParams.GetStringPtr("subcategory"),
-		LastEvaluated: baseParams.GetStringPtr("last_evaluated"),
-		Level:         baseParams.GetStringPtr("level"),
-		Public:        baseParams.GetBoolPtr("public"),
-		SortBy:        paramSort,
+func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.GetDictionariesV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
 	}
 	if err := validate.ValidateStruct(&params); err != nil {
 		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
 	}
 
-	queryInput, err := buildQueryInput(params)
-	if err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	owner, herr := listingOwner(api.MustGetMetaData(ctx), params)
+	if herr != nil {
+		return nil, herr
 	}
-	dynamoQueryInput, err := dbDynamo.BuildQueryInput(*queryInput)
+	queryInput, err := buildQueryInput(params, owner)
 	if err != nil {
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
 	}
-	result, err := dbDynamo.Query(ctx, applingodictionary.TableName, dynamoQueryInput)
-	if err != nil {
-		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	scope := cursorScope(queryInput.IndexName, params, owner)
+	if params.LastEvaluated != nil {
+		if queryInput.ExclusiveStartKey, err = paginator.Decode(scope, *params.LastEvaluated); err != nil {
+			return nil, api.CursorError(err)
+		}
+	}
+	limit := defaultPageLimit
+	if params.Limit != nil {
+		limit = *params.Limit
 	}
 
-	var (
-		wg      sync.WaitGroup
-		itemsCh = make(chan applingodictionary.SchemaItem, len(result.Items))
-	)
+	// FilterCondition is applied after Limit, so a page is collected from several queries,
+	// each query reads no more than the remaining items to keep LastEvaluatedKey exact.
 	response := applingoapi.DictionariesData{
-		Items: make([]applingoapi.DictionaryItemV1, 0, len(result.Items)),
+		Items: make([]applingoapi.DictionaryItemV1, 0, limit),
 	}
-	for _, item := range result.Items {
-		wg.Add(1)
-		go func(item map[string]types.AttributeValue) {
-			defer wg.Done()
+	for round := 0; round < maxQueryRounds; round++ {
+		queryInput.Limit = int32(limit - len(response.Items))
 
+		dynamoQueryInput, err := dbDynamo.BuildQueryInput(*queryInput)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		result, err := dbDynamo.Query(ctx, applingodictionary.TableName, dynamoQueryInput)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		for _, item := range result.Items {
 			var dict applingodictionary.SchemaItem
 			if err := attributevalue.UnmarshalMap(item, &dict); err != nil {
 				logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
-				return
+				continue
 			}
-			itemsCh <- dict
-		}(item)
-	}
-	go func() {
-		wg.Wait()
-		close(itemsCh)
-	}()
-
-	for item := range itemsCh {
-		response.Items = append(response.Items, applingoapi.DictionaryItemV1{
-			Category:    applingoapi.BaseCategoryEnum(item.Category),
-			Public:      applingodictionary.IntToBool(item.IsPublic),
-			Created:     int64(item.Created),
-			Description: item.Description,
-			Dictionary:  item.Dictionary,
-			Author:      item.Author,
-			Name:        item.Name,
-			Level:       item.Level,
-			Topic:       item.Topic,
-		})
-	}
-	if result.LastEvaluatedKey != nil {
-		var lastEvaluatedKeyMap map[string]interface{}
-		if err = attributevalue.UnmarshalMap(result.LastEvaluatedKey, &lastEvaluatedKeyMap); err != nil {
-			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			response.Items = append(response.Items, dictionaryItem(dict))
+		}
+
+		queryInput.ExclusiveStartKey = result.LastEvaluatedKey
+		if result.LastEvaluatedKey == nil || len(response.Items) >= limit {
+			break
 		}
-		lastEvaluatedKeyJSON, err := serializer.MarshalJSON(lastEvaluatedKeyMap)
+	}
+
+	if queryInput.ExclusiveStartKey != nil {
+		cursor, err := paginator.Encode(scope, queryInput.ExclusiveStartKey)
 		if err != nil {
 			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
 		}
-		page := base64.StdEncoding.EncodeToString(lastEvaluatedKeyJSON)
-		response.LastEvaluated = &page
+		response.LastEvaluated = &cursor
+		response.HasMore = true
 	}
 	return openapi.DataResponseDictionaries(response), nil
 }
 
-func buildQueryInput(params applingoapi.GetDictionariesV1Params) (*cloud.QueryInput, error) {
+func dictionaryItem(item applingodictionary.SchemaItem) applingoapi.DictionaryItemV1 {
+	status := moderationStatus(item)
+	dict := applingoapi.DictionaryItemV1{
+		Id:          item.Id,
+		Category:    applingoapi.BaseCategoryEnum(item.Category),
+		Public:      applingodictionary.IntToBool(item.IsPublic),
+		Created:     int64(item.Created),
+		Description: item.Description,
+		Dictionary:  item.Dictionary,
+		Author:      item.Author,
+		Name:        item.Name,
+		Level:       item.Level,
+		Topic:       item.Topic,
+		Subcategory: item.Subcategory,
+		Rating:      int32(item.Rating),
+		Version:     item.Version,
+		Status:      &status,
+	}
+	if item.ReviewNote != "" {
+		dict.ReviewNote = &item.ReviewNote
+	}
+	if item.CurrentVersion != 0 {
+		dict.CurrentVersion = &item.CurrentVersion
+	}
+	if item.PinnedVersion != 0 {
+		dict.PinnedVersion = &item.PinnedVersion
+	}
+	return dict
+}
+
+var errPrivateListing = errors.New("only users can list dictionaries which are not public")
+
+// listingOwner returns the owner whose dictionaries are listed, empty for public listings and managers.
+// Dictionaries which are not public are drafts and reviews of their owners, so users see only their own.
+func listingOwner(meta api.MetaData, params applingoapi.GetDictionariesV1Params) (string, *api.HandleError) {
+	if params.Public == nil || *params.Public || meta.HasPermissions(privilegedRole) {
+		return "", nil
+	}
+	if !meta.IsUser() {
+		return "", &api.HandleError{Status: http.StatusForbidden, Err: errPrivateListing, Message: errPrivateListing.Error()}
+	}
+	return meta.GetIdentifier(), nil
+}
+
+// cursorScope binds pagination cursor to the index and filters of the listing.
+func cursorScope(indexName string, params applingoapi.GetDictionariesV1Params, owner string) api.CursorScope {
+	filter := map[string]string{
+		"public": strconv.FormatBool(params.Public == nil || *params.Public),
+	}
+	if owner != "" {
+		filter["owner"] = owner
+	}
+	if params.Level != nil {
+		filter["level"] = *params.Level
+	}
+	if params.Subcategory != nil {
+		filter["subcategory"] = *params.Subcategory
+	}
+	return api.CursorScope{Index: indexName, Filter: filter}
+}
+
+// buildQueryInput returns the listing query, a non empty owner keeps only dictionaries of the owner.
+func buildQueryInput(params applingoapi.GetDictionariesV1Params, owner string) (*cloud.QueryInput, error) {
 	qb := applingodictionary.NewQueryBuilder()
 
 	isPublic := true
@@ -154,22 +196,12 @@ func buildQueryInput(params applingoapi.GetDictionariesV1Params) (*cloud.QueryIn
 	}
 	qb.OrderByDesc()
 
-	if params.LastEvaluated != nil {
-		lastEvaluatedKeyJSON, err := base64.StdEncoding.DecodeString(*params.LastEvaluated)
-		if err != nil {
-			return nil, errors.New("invalid last_evaluated key: unable to decode base64")
-		}
-		var lastEvaluatedKeyMap map[string]types.AttributeValue
-		if err := json.Unmarshal(lastEvaluatedKeyJSON, &lastEvaluatedKeyMap); err != nil {
-			return nil, errors.New("invalid last_evaluated key: unable to unmarshal JSON")
-		}
-		qb.StartFrom(lastEvaluatedKeyMap)
-	}
-	qb.Limit(pageLimit)
-
 	additionalFilter := expression.Name("dictionary").AttributeExists().And(
 		expression.Name("dictionary").NotEqual(expression.Value("")),
 	)
+	if owner != "" {
+		additionalFilter = additionalFilter.And(expression.Name("owner").Equal(expression.Value(owner)))
+	}
 	indexName, keyCondition, filterCondition, exclusiveStartKey, err := qb.Build()
 	if err != nil {
 		return nil, err
@@ -186,7 +218,6 @@ func buildQueryInput(params applingoapi.GetDictionariesV1Params) (*cloud.QueryIn
 		KeyCondition:      keyCondition,
 		FilterCondition:   filterCond,
 		ProjectionFields:  applingodictionary.IndexProjections[indexName],
-		Limit:             pageLimit,
 		ScanForward:       false,
 		ExclusiveStartKey: exclusiveStartKey,
 	}, nil
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var (
+	errVersionConflict = errors.New("dictionary was changed, reload it and retry")
+	errMoveConflict    = errors.New("dictionary was changed or already exists in the subcategory")
+)
+
+func handlePatch(ctx context.Context, logger zerolog.Logger, req applingoapi.RequestPatchDictionariesV1, baseParams openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.PatchDictionariesV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+
+	key := dictionaryKey(generateDictionaryID(params.Name, params.Author), params.Subcategory)
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableName, key)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get item for update")}
+	}
+	if result.Item == nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found"), Message: "item not found"}
+	}
+	var item applingodictionary.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if item.DeletedAt != 0 {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item is in trash"), Message: "item not found"}
+	}
+	if herr := authorizeMutation(ctx, logger, "update", item); herr != nil {
+		return nil, herr
+	}
+	if item.Version != req.Version {
+		return nil, &api.HandleError{Status: http.StatusConflict, Err: errVersionConflict, Message: errVersionConflict.Error()}
+	}
+
+	applyPatch(&item, req, api.MustGetMetaData(ctx).HasPermissions(privilegedRole))
+	conflict := errVersionConflict
+	if item.Subcategory == params.Subcategory {
+		err = updateDictionary(ctx, key, item, req.Version)
+	} else {
+		conflict = errMoveConflict
+		err = moveDictionary(ctx, key, item, req.Version)
+	}
+	if err != nil {
+		if cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusConflict, Err: err, Message: conflict.Error()}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	logger.Info().
+		Str("id", item.Id).
+		Str("subcategory", item.Subcategory).
+		Str("status", item.Status).
+		Int("version", item.Version).
+		Msg("Dictionary updated")
+
+	return applingoapi.ResponsePatchDictionariesV1{Data: dictionaryItem(item)}, nil
+}
+
+// applyPatch sets changed fields, moves the dictionary through moderation and bumps the version.
+func applyPatch(item *applingodictionary.SchemaItem, req applingoapi.RequestPatchDictionariesV1, privileged bool) {
+	if req.Description != nil {
+		item.Description = *req.Description
+	}
+	if req.Topic != nil {
+		item.Topic = *req.Topic
+	}
+	if req.Level != nil {
+		item.Level = *req.Level
+	}
+	if req.Subcategory != nil {
+		item.Subcategory = *req.Subcategory
+	}
+	public := requestedPublic(*item)
+	if req.Public != nil {
+		public = *req.Public
+	}
+	setStatus(item, nextStatus(moderationStatus(*item), public, privileged))
+	item.Version++
+}
+
+// versionCondition matches the item read by the client, items created before versioning have no version.
+func versionCondition(version int) expression.ConditionBuilder {
+	cond := expression.Name("version").Equal(expression.Value(version))
+	if version == 0 {
+		cond = expression.AttributeNotExists(expression.Name("version")).Or(cond)
+	}
+	return cond
+}
+
+func updateDictionary(ctx context.Context, key map[string]types.AttributeValue, item applingodictionary.SchemaItem, version int) error {
+	update := expression.
+		Set(expression.Name("description"), expression.Value(item.Description)).
+		Set(expression.Name("topic"), expression.Value(item.Topic)).
+		Set(expression.Name("level"), expression.Value(item.Level)).
+		Set(expression.Name("is_public"), expression.Value(item.IsPublic)).
+		Set(expression.Name("level#is_public"), expression.Value(item.LevelIsPublic)).
+		Set(expression.Name("subcategory#is_public"), expression.Value(item.SubcategoryIsPublic)).
+		Set(expression.Name("level#subcategory#is_public"), expression.Value(item.LevelSubcategoryIsPublic)).
+		Set(expression.Name("status"), expression.Value(item.Status)).
+		Set(expression.Name("version"), expression.Value(item.Version))
+
+	return dbDynamo.Update(ctx, applingodictionary.TableName, key, update, existingCondition().And(versionCondition(version)))
+}
+
+// moveDictionary changes subcategory, which is part of the primary key,
+// by writing the new item and deleting the old one in one transaction.
+func moveDictionary(ctx context.Context, oldKey map[string]types.AttributeValue, item applingodictionary.SchemaItem, version int) error {
+	// versions of the old key are removed with it, the file is converted again as the first version of the new key.
+	if item.CurrentVersion != 0 {
+		item.Dictionary = ""
+		item.CurrentVersion = 0
+		item.PinnedVersion = 0
+	}
+	dynamoItem, err := applingodictionary.PutItem(item)
+	if err != nil {
+		return err
+	}
+	trash.Omit(dynamoItem)
+	return dbDynamo.TransactWrite(ctx, []cloud.TransactWriteItem{
+		{
+			Table:     applingodictionary.TableName,
+			Item:      dynamoItem,
+			Condition: expression.AttributeNotExists(expression.Name("id")),
+		},
+		{
+			Table:     applingodictionary.TableName,
+			Key:       oldKey,
+			Condition: existingCondition().And(versionCondition(version)),
+		},
+	})
+}
+
+func dictionaryKey(id, subcategory string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id":          &types.AttributeValueMemberS{Value: id},
+		"subcategory": &types.AttributeValueMemberS{Value: subcategory},
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -14,30 +13,15 @@ import (
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
-	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/rs/zerolog"
 )
 
-func handlePost(ctx context.Context, logger zerolog.Logger, body json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
-	if api.MustGetMetaData(ctx).IsDevice() || !api.MustGetMetaData(ctx).HasPermissions(auth.User) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
-	var req applingoapi.RequestPostDictionariesV1
-	if err := serializer.UnmarshalJSON(body, &req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	if err := validate.ValidateStruct(&req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	levelSubcategoryIsPublic := fmt.Sprintf("%s#%s#%d", req.Level, req.Subcategory, applingodictionary.BoolToInt(req.Public))
-	subcategoryIsPublic := fmt.Sprintf("%s#%d", req.Subcategory, applingodictionary.BoolToInt(req.Public))
-	levelIsPublic := fmt.Sprintf("%s#%d", req.Level, applingodictionary.BoolToInt(req.Public))
-
+func handlePost(ctx context.Context, logger zerolog.Logger, req applingoapi.RequestPostDictionariesV1, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	meta := api.MustGetMetaData(ctx)
 	item := applingodictionary.SchemaItem{
 		Id:          generateDictionaryID(req.Name, req.Author),
 		Name:        req.Name,
@@ -46,20 +30,24 @@ func handlePost(ctx context.Context, logger zerolog.Logger, body json.RawMessage
 		Category:    string(req.Category),
 		Subcategory: req.Subcategory,
 		Description: req.Description,
-		IsPublic:    applingodictionary.BoolToInt(req.Public),
 		Level:       req.Level,
+		Topic:       req.Topic,
 		Created:     int(time.Now().Unix()),
 		Rating:      0,
-
-		// Composite keys
-		LevelSubcategoryIsPublic: levelSubcategoryIsPublic,
-		LevelIsPublic:            levelIsPublic,
-		SubcategoryIsPublic:      subcategoryIsPublic,
+		Version:     1,
+		Owner:       meta.GetIdentifier(),
+	}
+	// public dictionaries wait for review, dictionaries of privileged roles are published at once.
+	status := nextStatus(applingoapi.StatusDraft, req.Public, false)
+	if req.Public && meta.HasPermissions(privilegedRole) {
+		status = applingoapi.StatusApproved
 	}
+	setStatus(&item, status)
 	dynamoItem, err := applingodictionary.PutItem(item)
 	if err != nil {
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
 	}
+	trash.Omit(dynamoItem)
 
 	if err = dbDynamo.Put(
 		ctx,
@@ -69,13 +57,20 @@ func handlePost(ctx context.Context, logger zerolog.Logger, body json.RawMessage
 	); err != nil {
 		var conditionErr *types.ConditionalCheckFailedException
 		if errors.As(err, &conditionErr) {
-			return nil, &api.HandleError{Status: http.StatusConflict, Err: err}
+			return nil, &api.HandleError{Status: http.StatusConflict, Err: err, Message: "item already exists"}
 		}
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
 	}
 	return openapi.DataResponseSuccess, nil
 }
 
+// setCompositeKeys fills index keys derived from level, subcategory and visibility.
+func setCompositeKeys(item *applingodictionary.SchemaItem) {
+	item.LevelSubcategoryIsPublic = fmt.Sprintf("%s#%s#%d", item.Level, item.Subcategory, item.IsPublic)
+	item.SubcategoryIsPublic = fmt.Sprintf("%s#%d", item.Subcategory, item.IsPublic)
+	item.LevelIsPublic = fmt.Sprintf("%s#%d", item.Level, item.IsPublic)
+}
+
 func generateDictionaryID(name, author string) string {
 	hash := md5.New()
 	hash.Write([]byte(name + "-" + author))
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionaryrating"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog"
+)
+
+// handlePostRatings records one vote per identity and adds it to the rating of the dictionary in one transaction,
+// the rating is the range key of *ByRatingIndex, so listings pick it up without extra writes.
+func handlePostRatings(ctx context.Context, _ zerolog.Logger, req applingoapi.RequestPostRatingsV1, _ openapi.QueryParams, pathParams api.PathParams) (any, *api.HandleError) {
+	id, err := pathParams.GetString("id")
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err = validate.ValidateField(id, "len=32,hexadecimal"); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err, Message: "invalid dictionary id"}
+	}
+	meta := api.MustGetMetaData(ctx)
+	identity, herr := voterIdentity(meta)
+	if herr != nil {
+		return nil, herr
+	}
+
+	dictionaries, err := dictionariesByID(ctx, id)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	updates := ratingUpdates(meta, dictionaries, int(req.Value))
+	if len(updates) == 0 {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found"), Message: "item not found"}
+	}
+
+	vote, err := applingodictionaryrating.PutItem(applingodictionaryrating.SchemaItem{
+		DictionaryId: id,
+		Identity:     identity,
+		Value:        int(req.Value),
+		Created:      int(time.Now().Unix()),
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	// the vote goes first, its failed condition is told apart from a dictionary removed meanwhile.
+	items := []cloud.TransactWriteItem{{
+		Table:     applingodictionaryrating.TableName,
+		Item:      vote,
+		Condition: expression.AttributeNotExists(expression.Name("identity")),
+	}}
+	items = append(items, updates...)
+	if err = dbDynamo.TransactWrite(ctx, items); err != nil {
+		if cloud.IsTransactConditionFailed(err, 0) {
+			return nil, &api.HandleError{Status: http.StatusConflict, Err: err, Message: "dictionary is already rated"}
+		}
+		if cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusNotFound, Err: err, Message: "item not found"}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseSuccess, nil
+}
+
+// voterIdentity returns the key of the vote, users vote by JWT identifier and devices by their key ID.
+// The shared device token is the same in every app, so its requests cannot vote.
+func voterIdentity(meta api.MetaData) (string, *api.HandleError) {
+	if !meta.HasIdentifier() {
+		return "", &api.HandleError{Status: http.StatusForbidden, Err: errors.New("voter is not identified"), Message: "registered device key is required to vote"}
+	}
+	if meta.IsUser() {
+		return "user#" + meta.GetIdentifier(), nil
+	}
+	return "device#" + meta.GetIdentifier(), nil
+}
+
+// ratingUpdates returns rating updates of the dictionaries the caller may vote on,
+// dictionaries with the same name and author share the id and the votes.
+// Trashed and hidden dictionaries are skipped, the condition keeps a dictionary
+// hidden meanwhile out of the vote of callers who see it only while it is public.
+func ratingUpdates(meta api.MetaData, dictionaries []applingodictionary.SchemaItem, value int) []cloud.TransactWriteItem {
+	var updates []cloud.TransactWriteItem
+	for _, dict := range dictionaries {
+		if dict.DeletedAt != 0 || !meta.CanView(dict.Owner, applingodictionary.IntToBool(dict.IsPublic)) {
+			continue
+		}
+		condition := expression.AttributeExists(expression.Name("id"))
+		if !meta.CanManage(dict.Owner) {
+			condition = condition.And(expression.Name("is_public").Equal(expression.Value(applingodictionary.BoolToInt(true))))
+		}
+		update := expression.Add(expression.Name("rating"), expression.Value(value))
+		updates = append(updates, cloud.TransactWriteItem{
+			Table: applingodictionary.TableName,
+			Key: map[string]types.AttributeValue{
+				"id":          &types.AttributeValueMemberS{Value: dict.Id},
+				"subcategory": &types.AttributeValueMemberS{Value: dict.Subcategory},
+			},
+			Update:    &update,
+			Condition: condition,
+		})
+	}
+	return updates
+}
+
+// dictionariesByID returns the dictionaries with the id with attributes deciding who may vote on them.
+func dictionariesByID(ctx context.Context, id string) ([]applingodictionary.SchemaItem, error) {
+	queryInput, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+		KeyCondition:     expression.Key("id").Equal(expression.Value(id)),
+		ProjectionFields: []string{"id", "subcategory", "is_public", "owner", trash.AttrDeletedAt},
+		Limit:            10,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := dbDynamo.Query(ctx, applingodictionary.TableName, queryInput)
+	if err != nil {
+		return nil, err
+	}
+
+	dictionaries := make([]applingodictionary.SchemaItem, 0, len(result.Items))
+	if err = attributevalue.UnmarshalListOfMaps(result.Items, &dictionaries); err != nil {
+		return nil, err
+	}
+	return dictionaries, nil
+}
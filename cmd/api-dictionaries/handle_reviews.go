@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var errNotPending = errors.New("dictionary is not pending review")
+
+// handleGetReviews lists dictionaries in the moderation status, oldest first, so the queue is handled in order.
+func handleGetReviews(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.GetDictionariesReviewsV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	status := applingoapi.StatusPending
+	if params.Status != nil {
+		status = *params.Status
+	}
+	limit := defaultPageLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	// trashed dictionaries keep the status, the filter skips them.
+	queryInput := cloud.QueryInput{
+		IndexName:       applingodictionary.IndexStatusByDateIndex,
+		KeyCondition:    expression.Key("status").Equal(expression.Value(string(status))),
+		FilterCondition: trash.NotDeleted(),
+		Limit:           int32(limit),
+		ScanForward:     true,
+	}
+	scope := api.CursorScope{Index: queryInput.IndexName, Filter: map[string]string{"status": string(status)}}
+	if params.LastEvaluated != nil {
+		var err error
+		if queryInput.ExclusiveStartKey, err = paginator.Decode(scope, *params.LastEvaluated); err != nil {
+			return nil, api.CursorError(err)
+		}
+	}
+
+	dynamoQueryInput, err := dbDynamo.BuildQueryInput(queryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	result, err := dbDynamo.Query(ctx, applingodictionary.TableName, dynamoQueryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	response := applingoapi.DictionariesData{
+		Items: make([]applingoapi.DictionaryItemV1, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		var dict applingodictionary.SchemaItem
+		if err := attributevalue.UnmarshalMap(item, &dict); err != nil {
+			logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
+			continue
+		}
+		response.Items = append(response.Items, dictionaryItem(dict))
+	}
+	if result.LastEvaluatedKey != nil {
+		cursor, err := paginator.Encode(scope, result.LastEvaluatedKey)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		response.LastEvaluated = &cursor
+		response.HasMore = true
+	}
+	return openapi.DataResponseDictionaries(response), nil
+}
+
+// handlePostReviews approves or rejects a pending dictionary, approval puts it into public indexes.
+func handlePostReviews(ctx context.Context, logger zerolog.Logger, req applingoapi.RequestPostReviewsV1, _ openapi.QueryParams, pathParams api.PathParams) (any, *api.HandleError) {
+	id, err := pathParams.GetString("id")
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err = validate.ValidateField(id, "len=32,hexadecimal"); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err, Message: "invalid dictionary id"}
+	}
+	if req.Decision == applingoapi.DecisionReject && req.Note == nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.New("note is required"), Message: "note is required to reject a dictionary"}
+	}
+
+	key := dictionaryKey(id, req.Subcategory)
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableName, key)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get item for review")}
+	}
+	if result.Item == nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found"), Message: "item not found"}
+	}
+	var item applingodictionary.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if item.DeletedAt != 0 {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item is in trash"), Message: "item not found"}
+	}
+	if moderationStatus(item) != applingoapi.StatusPending {
+		return nil, &api.HandleError{Status: http.StatusConflict, Err: errNotPending, Message: errNotPending.Error()}
+	}
+	if item.Version != req.Version {
+		return nil, &api.HandleError{Status: http.StatusConflict, Err: errVersionConflict, Message: errVersionConflict.Error()}
+	}
+
+	meta := api.MustGetMetaData(ctx)
+	status := applingoapi.StatusRejected
+	if req.Decision == applingoapi.DecisionApprove {
+		status = applingoapi.StatusApproved
+	}
+	setStatus(&item, status)
+	item.ReviewNote = ""
+	if req.Note != nil {
+		item.ReviewNote = *req.Note
+	}
+	item.ReviewedBy = meta.GetIdentifier()
+	item.ReviewedAt = int(time.Now().Unix())
+	item.Version++
+
+	update := expression.
+		Set(expression.Name("status"), expression.Value(item.Status)).
+		Set(expression.Name("is_public"), expression.Value(item.IsPublic)).
+		Set(expression.Name("level#is_public"), expression.Value(item.LevelIsPublic)).
+		Set(expression.Name("subcategory#is_public"), expression.Value(item.SubcategoryIsPublic)).
+		Set(expression.Name("level#subcategory#is_public"), expression.Value(item.LevelSubcategoryIsPublic)).
+		Set(expression.Name("review_note"), expression.Value(item.ReviewNote)).
+		Set(expression.Name("reviewed_by"), expression.Value(item.ReviewedBy)).
+		Set(expression.Name("reviewed_at"), expression.Value(item.ReviewedAt)).
+		Set(expression.Name("version"), expression.Value(item.Version))
+
+	if err = dbDynamo.Update(ctx, applingodictionary.TableName, key, update, existingCondition().And(versionCondition(req.Version))); err != nil {
+		if cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusConflict, Err: err, Message: errVersionConflict.Error()}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	logger.Info().
+		Str("audit", "dictionary").
+		Str("action", "review").
+		Str("id", item.Id).
+		Str("subcategory", item.Subcategory).
+		Str("owner", item.Owner).
+		Str("actor", meta.GetIdentifier()).
+		Str("role", auth.RoleNames[meta.GetRole()]).
+		Str("status", item.Status).
+		Msg("Dictionary reviewed")
+
+	return &api.Response{Status: http.StatusOK, Body: applingoapi.ResponsePostReviewsV1{Data: dictionaryItem(item)}}, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionarysearch"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/search"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultSearchLimit = 20
+	// maxSearchTokens bounds index queries per request, extra words are ignored.
+	maxSearchTokens = 5
+	// maxSearchRows bounds index rows read per query word, very short words may match more,
+	// such responses are marked truncated.
+	maxSearchRows = 1000
+)
+
+// searchHit is a dictionary matched by the query with its accumulated relevance.
+type searchHit struct {
+	row       applingodictionarysearch.SchemaItem
+	relevance float64
+}
+
+func handleSearch(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.GetDictionariesSearchV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	limit := defaultSearchLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	tokens := search.Tokenize(params.Q)
+	if len(tokens) == 0 {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.New("query has no searchable words"), Message: "query has no searchable words"}
+	}
+	if len(tokens) > maxSearchTokens {
+		tokens = tokens[:maxSearchTokens]
+	}
+
+	var (
+		wg        sync.WaitGroup
+		results   = make([]map[string]searchHit, len(tokens))
+		truncated = make([]bool, len(tokens))
+		errs      = make([]error, len(tokens))
+	)
+	for i, token := range tokens {
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			results[i], truncated[i], errs[i] = searchToken(ctx, logger, token)
+		}(i, token)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+	}
+
+	// every query word must match, scores of the words are summed.
+	hits := results[0]
+	for _, other := range results[1:] {
+		for key, hit := range hits {
+			match, ok := other[key]
+			if !ok {
+				delete(hits, key)
+				continue
+			}
+			hit.relevance += match.relevance
+			hits[key] = hit
+		}
+	}
+
+	ranked := make([]searchHit, 0, len(hits))
+	for _, hit := range hits {
+		hit.relevance = search.Rank(hit.relevance, hit.row.Rating)
+		ranked = append(ranked, hit)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].relevance != ranked[j].relevance {
+			return ranked[i].relevance > ranked[j].relevance
+		}
+		return ranked[i].row.Created > ranked[j].row.Created
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	response := applingoapi.DictionariesSearchData{
+		Items: make([]applingoapi.DictionaryItemV1, 0, len(ranked)),
+	}
+	for _, t := range truncated {
+		response.Truncated = response.Truncated || t
+	}
+	for _, hit := range ranked {
+		response.Items = append(response.Items, searchItem(hit.row))
+	}
+	return openapi.DataResponseDictionariesSearch(response), nil
+}
+
+// searchToken reads index rows of the exact query token first and then rows of longer tokens
+// starting with it, returns the best matching row of every dictionary. Reading stops after
+// maxSearchRows rows, truncated reports that matching rows were left unread.
+func searchToken(ctx context.Context, logger zerolog.Logger, token string) (map[string]searchHit, bool, error) {
+	prefix := expression.Key("prefix").Equal(expression.Value(search.Prefix(token)))
+	// terms are "token#id#subcategory", longer tokens continue with a letter or digit, which sort after "#".
+	conditions := []expression.KeyConditionBuilder{
+		prefix.And(expression.Key("term").BeginsWith(token + "#")),
+		prefix.And(expression.Key("term").Between(expression.Value(token+"$"), expression.Value(token+string(utf8.MaxRune)))),
+	}
+
+	hits := make(map[string]searchHit)
+	read := 0
+	for _, keyCondition := range conditions {
+		input := cloud.QueryInput{
+			KeyCondition: keyCondition,
+			ScanForward:  true,
+		}
+		for {
+			if read >= maxSearchRows {
+				return hits, true, nil
+			}
+			input.Limit = int32(maxSearchRows - read)
+
+			queryInput, err := dbDynamo.BuildQueryInput(input)
+			if err != nil {
+				return nil, false, err
+			}
+			result, err := dbDynamo.Query(ctx, applingodictionarysearch.TableName, queryInput)
+			if err != nil {
+				return nil, false, err
+			}
+			read += len(result.Items)
+
+			for _, item := range result.Items {
+				var row applingodictionarysearch.SchemaItem
+				if err := attributevalue.UnmarshalMap(item, &row); err != nil {
+					logger.Warn().Err(err).Msg("Failed to unmarshal search index row")
+					continue
+				}
+				relevance := search.Relevance(token, row.Token, row.Weight)
+				key := row.Id + "#" + row.Subcategory
+				if hit, ok := hits[key]; !ok || relevance > hit.relevance {
+					hits[key] = searchHit{row: row, relevance: relevance}
+				}
+			}
+			if result.LastEvaluatedKey == nil {
+				break
+			}
+			input.ExclusiveStartKey = result.LastEvaluatedKey
+		}
+	}
+	return hits, false, nil
+}
+
+func searchItem(row applingodictionarysearch.SchemaItem) applingoapi.DictionaryItemV1 {
+	return applingoapi.DictionaryItemV1{
+		Id:          row.Id,
+		Category:    applingoapi.BaseCategoryEnum(row.Category),
+		Public:      true,
+		Created:     int64(row.Created),
+		Description: row.Description,
+		Dictionary:  row.Dictionary,
+		Author:      row.Author,
+		Name:        row.Name,
+		Level:       row.Level,
+		Topic:       row.Topic,
+		Subcategory: row.Subcategory,
+		Rating:      int32(row.Rating),
+		Version:     row.Version,
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingosubcategory"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var errNotInTrash = errors.New("item is not in trash")
+
+// trashTables are tables with soft deletion by kind of the item.
+var trashTables = map[applingoapi.BaseTrashKindEnum]string{
+	applingoapi.TrashDictionary:  applingodictionary.TableName,
+	applingoapi.TrashSubcategory: applingosubcategory.TableName,
+}
+
+// handleGetTrash lists trashed items of the kind from the sparse trash index, recently deleted first.
+func handleGetTrash(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.GetTrashV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	limit := defaultPageLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	table := trashTables[params.Kind]
+	queryInput := cloud.QueryInput{
+		IndexName:    trash.IndexName,
+		KeyCondition: expression.Key(trash.AttrIsDeleted).Equal(expression.Value(1)),
+		Limit:        int32(limit),
+		ScanForward:  false,
+	}
+	scope := api.CursorScope{Index: trash.IndexName, Filter: map[string]string{"kind": string(params.Kind)}}
+	if params.LastEvaluated != nil {
+		var err error
+		if queryInput.ExclusiveStartKey, err = paginator.Decode(scope, *params.LastEvaluated); err != nil {
+			return nil, api.CursorError(err)
+		}
+	}
+
+	dynamoQueryInput, err := dbDynamo.BuildQueryInput(queryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	result, err := dbDynamo.Query(ctx, table, dynamoQueryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	response := applingoapi.TrashData{
+		Items: make([]applingoapi.TrashItemV1, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		trashItem, err := trashItem(params.Kind, item)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
+			continue
+		}
+		response.Items = append(response.Items, trashItem)
+	}
+	if result.LastEvaluatedKey != nil {
+		cursor, err := paginator.Encode(scope, result.LastEvaluatedKey)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		response.LastEvaluated = &cursor
+		response.HasMore = true
+	}
+	return openapi.DataResponseTrash(response), nil
+}
+
+// handlePostTrashRestore moves the item out of trash and puts it back into listing indexes.
+func handlePostTrashRestore(ctx context.Context, logger zerolog.Logger, req applingoapi.RequestPostTrashRestoreV1, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var err error
+	switch req.Kind {
+	case applingoapi.TrashDictionary:
+		if req.Subcategory == nil {
+			return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.New("subcategory is required"), Message: "subcategory is required to restore a dictionary"}
+		}
+		err = restoreDictionary(ctx, dictionaryKey(req.Id, *req.Subcategory))
+	case applingoapi.TrashSubcategory:
+		err = restoreSubcategory(ctx, req.Id)
+	}
+	if err != nil {
+		if errors.Is(err, errNotInTrash) || cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusNotFound, Err: err, Message: errNotInTrash.Error()}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	meta := api.MustGetMetaData(ctx)
+	logger.Info().
+		Str("audit", "trash").
+		Str("action", "restore").
+		Str("kind", string(req.Kind)).
+		Str("id", req.Id).
+		Str("actor", meta.GetIdentifier()).
+		Str("role", auth.RoleNames[meta.GetRole()]).
+		Msg("Item restored from trash")
+
+	return &api.Response{Status: http.StatusOK, Body: openapi.DataResponseSuccess}, nil
+}
+
+func trashItem(kind applingoapi.BaseTrashKindEnum, item map[string]types.AttributeValue) (applingoapi.TrashItemV1, error) {
+	if kind == applingoapi.TrashSubcategory {
+		var sub applingosubcategory.SchemaItem
+		if err := attributevalue.UnmarshalMap(item, &sub); err != nil {
+			return applingoapi.TrashItemV1{}, err
+		}
+		return applingoapi.TrashItemV1{
+			Kind:      kind,
+			Id:        sub.Id,
+			DeletedAt: int64(sub.DeletedAt),
+			PurgeAt:   int64(sub.Ttl),
+			DeletedBy: &sub.DeletedBy,
+			Code:      &sub.Code,
+			Side:      &sub.TrashedSide,
+		}, nil
+	}
+
+	var dict applingodictionary.SchemaItem
+	if err := attributevalue.UnmarshalMap(item, &dict); err != nil {
+		return applingoapi.TrashItemV1{}, err
+	}
+	return applingoapi.TrashItemV1{
+		Kind:        kind,
+		Id:          dict.Id,
+		DeletedAt:   int64(dict.DeletedAt),
+		PurgeAt:     int64(dict.Ttl),
+		DeletedBy:   &dict.DeletedBy,
+		Name:        &dict.Name,
+		Author:      &dict.Author,
+		Subcategory: &dict.Subcategory,
+	}, nil
+}
+
+// restoreDictionary sets visibility saved on deletion back and recomputes listing keys.
+func restoreDictionary(ctx context.Context, key map[string]types.AttributeValue) error {
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableName, key)
+	if err != nil {
+		return errors.Wrap(err, "failed to get item for restore")
+	}
+	if result.Item == nil {
+		return errNotInTrash
+	}
+	var item applingodictionary.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return err
+	}
+	if item.DeletedAt == 0 {
+		return errNotInTrash
+	}
+
+	item.IsPublic = item.TrashedIsPublic
+	setCompositeKeys(&item)
+	update := trash.Restore().
+		Set(expression.Name("is_public"), expression.Value(item.IsPublic)).
+		Set(expression.Name("level#is_public"), expression.Value(item.LevelIsPublic)).
+		Set(expression.Name("subcategory#is_public"), expression.Value(item.SubcategoryIsPublic)).
+		Set(expression.Name("level#subcategory#is_public"), expression.Value(item.LevelSubcategoryIsPublic)).
+		Remove(expression.Name("trashed_is_public"))
+
+	return dbDynamo.Update(ctx, applingodictionary.TableName, key, update, trash.Deleted())
+}
+
+// restoreSubcategory sets side saved on deletion back, so the subcategory returns to SideIndex.
+func restoreSubcategory(ctx context.Context, id string) error {
+	key := map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: id},
+	}
+	result, err := dbDynamo.Get(ctx, applingosubcategory.TableName, key)
+	if err != nil {
+		return errors.Wrap(err, "failed to get item for restore")
+	}
+	if result.Item == nil {
+		return errNotInTrash
+	}
+	var item applingosubcategory.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return err
+	}
+	if item.DeletedAt == 0 {
+		return errNotInTrash
+	}
+
+	update := trash.Restore().
+		Set(expression.Name("side"), expression.Value(item.TrashedSide)).
+		Remove(expression.Name("trashed_side"))
+
+	return dbDynamo.Update(ctx, applingosubcategory.TableName, key, update, trash.Deleted())
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionaryversion"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/history"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var errVersionNotFound = errors.New("dictionary version not found")
+
+// handleGetVersions lists file versions of the dictionary, newest first.
+func handleGetVersions(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams, pathParams api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.GetDictionariesVersionsV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	id, herr := dictionaryIDParam(pathParams)
+	if herr != nil {
+		return nil, herr
+	}
+	dict, herr := getActiveDictionary(ctx, dictionaryKey(id, params.Subcategory))
+	if herr != nil {
+		return nil, herr
+	}
+	if herr = authorizeView(ctx, dict); herr != nil {
+		return nil, herr
+	}
+	limit := defaultPageLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	queryInput := cloud.QueryInput{
+		KeyCondition: expression.Key("dictionary_key").Equal(expression.Value(history.Key(id, params.Subcategory))),
+		Limit:        int32(limit),
+		ScanForward:  false,
+	}
+	scope := api.CursorScope{Index: applingodictionaryversion.TableName, Filter: map[string]string{"id": id, "subcategory": params.Subcategory}}
+	if params.LastEvaluated != nil {
+		var err error
+		if queryInput.ExclusiveStartKey, err = paginator.Decode(scope, *params.LastEvaluated); err != nil {
+			return nil, api.CursorError(err)
+		}
+	}
+
+	dynamoQueryInput, err := dbDynamo.BuildQueryInput(queryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	result, err := dbDynamo.Query(ctx, applingodictionaryversion.TableName, dynamoQueryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	response := applingoapi.VersionsData{
+		Items: make([]applingoapi.VersionItemV1, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		var version applingodictionaryversion.SchemaItem
+		if err := attributevalue.UnmarshalMap(item, &version); err != nil {
+			logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
+			continue
+		}
+		response.Items = append(response.Items, applingoapi.VersionItemV1{
+			Number:     version.Number,
+			Dictionary: version.Dictionary,
+			Checksum:   version.Checksum,
+			Size:       version.Size,
+			Created:    int64(version.Created),
+			Current:    version.Number == dict.CurrentVersion,
+		})
+	}
+	if result.LastEvaluatedKey != nil {
+		cursor, err := paginator.Encode(scope, result.LastEvaluatedKey)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		response.LastEvaluated = &cursor
+		response.HasMore = true
+	}
+	return openapi.DataResponseVersions(response), nil
+}
+
+// handlePutVersionsCurrent serves an existing version as the dictionary file,
+// a pinned version is not replaced by new uploads, which makes rollbacks stick.
+func handlePutVersionsCurrent(ctx context.Context, logger zerolog.Logger, req applingoapi.RequestPutVersionsCurrentV1, _ openapi.QueryParams, pathParams api.PathParams) (any, *api.HandleError) {
+	id, herr := dictionaryIDParam(pathParams)
+	if herr != nil {
+		return nil, herr
+	}
+	key := dictionaryKey(id, req.Subcategory)
+	item, herr := getActiveDictionary(ctx, key)
+	if herr != nil {
+		return nil, herr
+	}
+	if herr = authorizeMutation(ctx, logger, "version", item); herr != nil {
+		return nil, herr
+	}
+	if item.Version != req.Version {
+		return nil, &api.HandleError{Status: http.StatusConflict, Err: errVersionConflict, Message: errVersionConflict.Error()}
+	}
+
+	result, err := dbDynamo.Get(ctx, applingodictionaryversion.TableName, map[string]types.AttributeValue{
+		"dictionary_key": &types.AttributeValueMemberS{Value: history.Key(id, req.Subcategory)},
+		"number":         &types.AttributeValueMemberN{Value: strconv.Itoa(req.Number)},
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get dictionary version")}
+	}
+	if result.Item == nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errVersionNotFound, Message: errVersionNotFound.Error()}
+	}
+	var version applingodictionaryversion.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &version); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	item.Dictionary = version.Dictionary
+	item.CurrentVersion = version.Number
+	item.PinnedVersion = 0
+	if req.Pinned {
+		item.PinnedVersion = version.Number
+	}
+	item.Version++
+
+	update := expression.
+		Set(expression.Name("dictionary"), expression.Value(item.Dictionary)).
+		Set(expression.Name("current_version"), expression.Value(item.CurrentVersion)).
+		Set(expression.Name("pinned_version"), expression.Value(item.PinnedVersion)).
+		Set(expression.Name("version"), expression.Value(item.Version))
+
+	if err = dbDynamo.Update(ctx, applingodictionary.TableName, key, update, existingCondition().And(versionCondition(req.Version))); err != nil {
+		if cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusConflict, Err: err, Message: errVersionConflict.Error()}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	meta := api.MustGetMetaData(ctx)
+	logger.Info().
+		Str("audit", "dictionary").
+		Str("action", "version").
+		Str("id", item.Id).
+		Str("subcategory", item.Subcategory).
+		Str("owner", item.Owner).
+		Str("actor", meta.GetIdentifier()).
+		Str("role", auth.RoleNames[meta.GetRole()]).
+		Int("current_version", item.CurrentVersion).
+		Bool("pinned", req.Pinned).
+		Msg("Dictionary version changed")
+
+	return applingoapi.ResponsePutVersionsCurrentV1{Data: dictionaryItem(item)}, nil
+}
+
+func dictionaryIDParam(pathParams api.PathParams) (string, *api.HandleError) {
+	id, err := pathParams.GetString("id")
+	if err != nil {
+		return "", &api.HandleError{Status: http.StatusBadRequest, Err: err}
+	}
+	if err = validate.ValidateField(id, "len=32,hexadecimal"); err != nil {
+		return "", &api.HandleError{Status: http.StatusBadRequest, Err: err, Message: "invalid dictionary id"}
+	}
+	return id, nil
+}
+
+// getActiveDictionary returns the dictionary by key, trashed dictionaries are not found.
+func getActiveDictionary(ctx context.Context, key map[string]types.AttributeValue) (applingodictionary.SchemaItem, *api.HandleError) {
+	var item applingodictionary.SchemaItem
+
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableName, key)
+	if err != nil {
+		return item, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get dictionary")}
+	}
+	if result.Item == nil {
+		return item, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found"), Message: "item not found"}
+	}
+	if err = attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return item, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if item.DeletedAt != 0 {
+		return item, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item is in trash"), Message: "item not found"}
+	}
+	return item, nil
+}
@@ -6,22 +6,26 @@ import (
 	"runtime/debug"
 
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 	"github.com/Mad-Pixels/applingo-api/pkg/validator"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
 var (
-	awsRegion = os.Getenv("AWS_REGION")
+	paginationSecret = os.Getenv("PAGINATION_SECRET")
+	awsRegion        = os.Getenv("AWS_REGION")
+
 	validate  *validator.Validator
 	dbDynamo  *cloud.Dynamo
+	paginator *api.Paginator
 )
 
 func init() {
 	debug.SetGCPercent(500)
 	validate = validator.New()
+	paginator = api.NewPaginator(paginationSecret)
 
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
 	if err != nil {
@@ -31,16 +35,26 @@ func init() {
 }
 
 func main() {
-	lambda.Start(
-		api.NewLambda(
-			api.Config{
-				EnableRequestLogging: true,
-			},
-			map[string]api.HandleFunc{
-				"GET /v1/dictionaries":    handleGet,
-				"POST /v1/dictionaries":   handlePost,
-				"DELETE /v1/dictionaries": handleDelete,
-			},
-		).Handle,
-	)
+	api.NewLambda(
+		api.Config{
+			EnableRequestLogging: true,
+			CORS:                 api.DefaultCORSConfig(),
+			Compression:          &api.CompressionConfig{},
+		},
+		map[string]api.HandleFunc{
+			"GET /v1/dictionaries":                       api.Chain(handleGet, api.RequireRole(auth.Device)),
+			"GET /v1/dictionaries/search":                api.Chain(handleSearch, api.RequireRole(auth.Device)),
+			"GET /v1/dictionaries/reviews":               api.Chain(handleGetReviews, api.RequireUser(auth.Manager)),
+			"POST /v1/dictionaries/{id}/reviews":         api.Chain(api.WithBody(validate, handlePostReviews), api.RequireUser(auth.Manager)),
+			"POST /v1/dictionaries":                      api.Chain(api.WithBody(validate, handlePost), api.RequireUser(auth.User)),
+			"POST /v1/dictionaries/{id}/ratings":         api.Chain(api.WithBody(validate, handlePostRatings), api.RequireRole(auth.Device)),
+			"GET /v1/dictionaries/{id}/versions":         api.Chain(handleGetVersions, api.RequireRole(auth.Device)),
+			"PUT /v1/dictionaries/{id}/versions/current": api.Chain(api.WithBody(validate, handlePutVersionsCurrent), api.RequireUser(auth.User)),
+			"PATCH /v1/dictionaries":                     api.Chain(api.WithBody(validate, handlePatch), api.RequireUser(auth.User)),
+			"DELETE /v1/dictionaries":                    api.Chain(handleDelete, api.RequireUser(auth.User)),
+			"GET /v1/trash":                              api.Chain(handleGetTrash, api.RequireUser(auth.Manager)),
+			"POST /v1/trash/restore":                     api.Chain(api.WithBody(validate, handlePostTrashRestore), api.RequireUser(auth.Manager)),
+		},
+		api.Timing(),
+	).Start()
 }
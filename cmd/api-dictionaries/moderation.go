@@ -0,0 +1,52 @@
+package main
+
+import (
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+)
+
+// Moderation states of a dictionary:
+//
+//	draft    - private, not reviewed;
+//	pending  - the author asked to publish, waits for a manager;
+//	approved - public, the only state listed in public indexes;
+//	rejected - publication declined, review_note explains why.
+//
+// Owners move dictionaries between draft and pending, managers approve or reject pending ones.
+
+// moderationStatus returns the status of the dictionary,
+// dictionaries created before moderation are approved when public.
+func moderationStatus(item applingodictionary.SchemaItem) applingoapi.BaseModerationStatusEnum {
+	if item.Status != "" {
+		return applingoapi.BaseModerationStatusEnum(item.Status)
+	}
+	if applingodictionary.IntToBool(item.IsPublic) {
+		return applingoapi.StatusApproved
+	}
+	return applingoapi.StatusDraft
+}
+
+// requestedPublic reports whether the author wants the dictionary to be public.
+func requestedPublic(item applingodictionary.SchemaItem) bool {
+	return moderationStatus(item) != applingoapi.StatusDraft
+}
+
+// nextStatus returns the status after a change of the dictionary in the current status.
+// Changes of privileged roles keep approval, other changes of a public dictionary go to review.
+func nextStatus(current applingoapi.BaseModerationStatusEnum, public, privileged bool) applingoapi.BaseModerationStatusEnum {
+	switch {
+	case !public:
+		return applingoapi.StatusDraft
+	case privileged && current == applingoapi.StatusApproved:
+		return applingoapi.StatusApproved
+	default:
+		return applingoapi.StatusPending
+	}
+}
+
+// setStatus sets the status and visibility, only approved dictionaries get public listing keys.
+func setStatus(item *applingodictionary.SchemaItem, status applingoapi.BaseModerationStatusEnum) {
+	item.Status = string(status)
+	item.IsPublic = applingodictionary.BoolToInt(status == applingoapi.StatusApproved)
+	setCompositeKeys(item)
+}
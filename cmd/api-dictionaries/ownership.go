@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// privilegedRole may change dictionaries of other users.
+const privilegedRole = auth.Manager
+
+var (
+	errNotOwner   = errors.New("only the owner or a manager can change the dictionary")
+	errNotVisible = errors.New("dictionary is not public")
+)
+
+// authorizeMutation allows the owner of the dictionary and privileged roles to change it,
+// every decision is logged for audit. Dictionaries created before ownership have no owner.
+func authorizeMutation(ctx context.Context, logger zerolog.Logger, action string, item applingodictionary.SchemaItem) *api.HandleError {
+	meta := api.MustGetMetaData(ctx)
+
+	reason := "denied"
+	switch {
+	case meta.IsOwner(item.Owner):
+		reason = "owner"
+	case meta.HasPermissions(privilegedRole):
+		reason = "role"
+	}
+	allowed := reason != "denied"
+
+	logger.Info().
+		Str("audit", "dictionary").
+		Str("action", action).
+		Str("id", item.Id).
+		Str("subcategory", item.Subcategory).
+		Str("owner", item.Owner).
+		Str("actor", meta.GetIdentifier()).
+		Str("role", auth.RoleNames[meta.GetRole()]).
+		Bool("allowed", allowed).
+		Str("reason", reason).
+		Msg("Dictionary mutation authorization")
+
+	if !allowed {
+		return &api.HandleError{Status: http.StatusForbidden, Err: errNotOwner, Message: errNotOwner.Error()}
+	}
+	return nil
+}
+
+// authorizeView allows everyone to see public dictionaries, others only the owner and privileged roles,
+// hidden dictionaries are reported as not found.
+func authorizeView(ctx context.Context, item applingodictionary.SchemaItem) *api.HandleError {
+	if api.MustGetMetaData(ctx).CanView(item.Owner, applingodictionary.IntToBool(item.IsPublic)) {
+		return nil
+	}
+	return &api.HandleError{Status: http.StatusNotFound, Err: errNotVisible, Message: "item not found"}
+}
@@ -3,26 +3,25 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingolevel"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/rs/zerolog"
 )
 
-const pageLimit = 6
+const (
+	pageLimit = 6
 
-func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).HasPermissions(auth.Device) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
+	// levels are changed only by deploys, clients revalidate with If-None-Match.
+	cacheControl = "private, max-age=3600"
+)
 
+func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
 	scanInput := dbDynamo.BuildScanInput(applingolevel.TableName, pageLimit, nil)
 	result, err := dbDynamo.Scan(ctx, applingolevel.TableName, scanInput)
 	if err != nil {
@@ -41,7 +40,7 @@ func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, _
 			Level: item.Level,
 		})
 	}
-	return openapi.DataResponseLevels(applingoapi.LevelsData{
+	return api.NewResponse(openapi.DataResponseLevels(applingoapi.LevelsData{
 		Items: items,
-	}), nil
+	})).WithCacheControl(cacheControl), nil
 }
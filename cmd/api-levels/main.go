@@ -6,9 +6,9 @@ import (
 	"runtime/debug"
 
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
@@ -28,14 +28,14 @@ func init() {
 }
 
 func main() {
-	lambda.Start(
-		api.NewLambda(
-			api.Config{
-				EnableRequestLogging: true,
-			},
-			map[string]api.HandleFunc{
-				"GET /v1/levels": handleGet,
-			},
-		).Handle,
-	)
+	api.NewLambda(
+		api.Config{
+			EnableRequestLogging: true,
+			CORS:                 api.DefaultCORSConfig(),
+		},
+		map[string]api.HandleFunc{
+			"GET /v1/levels": api.Chain(handleGet, api.RequireRole(auth.Device)),
+		},
+		api.Timing(),
+	).Start()
 }
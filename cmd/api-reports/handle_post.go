@@ -17,19 +17,7 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func handlePost(ctx context.Context, _ zerolog.Logger, raw json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).IsDevice() {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
-	var req applingoapi.RequestPostReportsV1
-	if err := serializer.UnmarshalJSON(raw, &req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	if err := validate.ValidateStruct(&req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-
+func handlePost(ctx context.Context, _ zerolog.Logger, req applingoapi.RequestPostReportsV1, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
 	var (
 		key  = time.Now().UTC().Format("logs-2006-01-02.json")
 		logs []applingoapi.RequestPostReportsV1
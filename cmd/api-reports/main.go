@@ -9,7 +9,6 @@ import (
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 	"github.com/Mad-Pixels/applingo-api/pkg/validator"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
@@ -33,14 +32,14 @@ func init() {
 }
 
 func main() {
-	lambda.Start(
-		api.NewLambda(
-			api.Config{
-				EnableRequestLogging: true,
-			},
-			map[string]api.HandleFunc{
-				"POST /v1/reports": handlePost,
-			},
-		).Handle,
-	)
+	api.NewLambda(
+		api.Config{
+			EnableRequestLogging: true,
+			CORS:                 api.DefaultCORSConfig(),
+		},
+		map[string]api.HandleFunc{
+			"POST /v1/reports": api.Chain(api.WithBody(validate, handlePost), api.RequireDevice()),
+		},
+		api.Timing(),
+	).Start()
 }
@@ -4,34 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingosubcategory"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
 
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
-func handleDelete(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
-	if api.MustGetMetaData(ctx).IsDevice() || !api.MustGetMetaData(ctx).HasPermissions(auth.User) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
-	validSideValues := map[applingoapi.BaseSideEnum]struct{}{
-		applingoapi.Front: {},
-		applingoapi.Back:  {},
-	}
-	paramSide, err := openapi.ParseEnumParam(baseParams.GetStringPtr("side"), validSideValues)
-	if err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.Wrap(err, "invalid value for 'side' param")}
-	}
-	params := applingoapi.DeleteSubcategoriesV1Params{
-		Code: baseParams.GetStringDefault("code", ""),
-		Side: paramSide,
+func handleDelete(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.DeleteSubcategoriesV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
 	}
 	if err := validate.ValidateStruct(&params); err != nil {
 		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
@@ -45,13 +37,38 @@ func handleDelete(ctx context.Context, logger zerolog.Logger, _ json.RawMessage,
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get item for deletion")}
 	}
 	if result.Item == nil {
-		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found")}
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found"), Message: "item not found"}
+	}
+	var item applingosubcategory.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if item.DeletedAt != 0 {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item is in trash"), Message: "item not found"}
 	}
 
-	if err := dbDynamo.Delete(ctx, applingosubcategory.TableName, map[string]types.AttributeValue{
-		"id": &types.AttributeValueMemberS{Value: id},
-	}); err != nil {
+	// the subcategory goes to trash without side, so it drops out of SideIndex.
+	update := trash.
+		Delete(api.MustGetMetaData(ctx).GetIdentifier(), time.Now(), "side").
+		Set(expression.Name("trashed_side"), expression.Value(item.Side))
+	if err = dbDynamo.Update(
+		ctx,
+		applingosubcategory.TableName,
+		map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		update,
+		expression.AttributeExists(expression.Name("id")).And(trash.NotDeleted()),
+	); err != nil {
+		if cloud.IsConditionFailed(err) {
+			return nil, &api.HandleError{Status: http.StatusNotFound, Err: err, Message: "item not found"}
+		}
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to delete item")}
 	}
+	logger.Info().
+		Str("id", id).
+		Str("code", item.Code).
+		Str("side", item.Side).
+		Msg("Subcategory moved to trash")
 	return nil, nil
 }
@@ -4,38 +4,31 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sort"
 	"sync"
 
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingosubcategory"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
-const pageLimit = 1000
+const (
+	pageLimit = 1000
 
-func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).HasPermissions(auth.Device) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
+	// clients revalidate with If-None-Match, order of items is stable to keep ETag stable.
+	cacheControl = "private, max-age=300"
+)
 
-	validSideValues := map[applingoapi.BaseSideEnum]struct{}{
-		applingoapi.Front: {},
-		applingoapi.Back:  {},
-	}
-	paramSide, err := openapi.ParseEnumParam(baseParams.GetStringPtr("side"), validSideValues)
-	if err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.Wrap(err, "invalid value for 'side' param")}
-	}
-	params := applingoapi.GetSubcategoriesV1Params{
-		Side: paramSide,
+func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
+	var params applingoapi.GetSubcategoriesV1Params
+	if err := baseParams.Decode(&params); err != nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
 	}
 	if err := validate.ValidateStruct(&params); err != nil {
 		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
@@ -81,6 +74,10 @@ func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, ba
 				logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
 				return
 			}
+			// the scan reads trashed subcategories too, SideIndex has none of them.
+			if category.DeletedAt != 0 {
+				return
+			}
 			itemsCh <- category
 		}(item)
 	}
@@ -101,7 +98,15 @@ func handleGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, ba
 			})
 		}
 	}
-	return openapi.DataResponseSubcategories(response), nil
+	sortSubcategories(response.FrontSide)
+	sortSubcategories(response.BackSide)
+	return api.NewResponse(openapi.DataResponseSubcategories(response)).WithCacheControl(cacheControl), nil
+}
+
+func sortSubcategories(items []applingoapi.SubcategoryItemV1) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Code < items[j].Code
+	})
 }
 
 func buildQueryInput(params applingoapi.GetSubcategoriesV1Params) (*cloud.QueryInput, error) {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"net/http"
 
@@ -12,27 +11,14 @@ import (
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
-	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/rs/zerolog"
 )
 
-func handlePost(ctx context.Context, _ zerolog.Logger, body json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
-	if api.MustGetMetaData(ctx).IsDevice() || !api.MustGetMetaData(ctx).HasPermissions(auth.User) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
-	var req applingoapi.RequestPostSubcategoriesV1
-	if err := serializer.UnmarshalJSON(body, &req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	if err := validate.ValidateStruct(&req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-
+func handlePost(ctx context.Context, _ zerolog.Logger, req applingoapi.RequestPostSubcategoriesV1, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
 	item := applingosubcategory.SchemaItem{
 		Id:          generateSubcategoryID(req.Code, string(req.Side)),
 		Code:        req.Code,
@@ -43,6 +29,7 @@ func handlePost(ctx context.Context, _ zerolog.Logger, body json.RawMessage, _ o
 	if err != nil {
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
 	}
+	trash.Omit(dynamoItem)
 	if err = dbDynamo.Put(
 		ctx,
 		applingosubcategory.TableName,
@@ -51,7 +38,7 @@ func handlePost(ctx context.Context, _ zerolog.Logger, body json.RawMessage, _ o
 	); err != nil {
 		var conditionErr *types.ConditionalCheckFailedException
 		if errors.As(err, &conditionErr) {
-			return nil, &api.HandleError{Status: http.StatusConflict, Err: err}
+			return nil, &api.HandleError{Status: http.StatusConflict, Err: err, Message: "item already exists"}
 		}
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
 	}
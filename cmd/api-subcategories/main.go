@@ -6,10 +6,10 @@ import (
 	"runtime/debug"
 
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 	"github.com/Mad-Pixels/applingo-api/pkg/validator"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
@@ -31,16 +31,17 @@ func init() {
 }
 
 func main() {
-	lambda.Start(
-		api.NewLambda(
-			api.Config{
-				EnableRequestLogging: true,
-			},
-			map[string]api.HandleFunc{
-				"GET /v1/subcategories":    handleGet,
-				"POST /v1/subcategories":   handlePost,
-				"DELETE /v1/subcategories": handleDelete,
-			},
-		).Handle,
-	)
+	api.NewLambda(
+		api.Config{
+			EnableRequestLogging: true,
+			CORS:                 api.DefaultCORSConfig(),
+			Compression:          &api.CompressionConfig{},
+		},
+		map[string]api.HandleFunc{
+			"GET /v1/subcategories":    api.Chain(handleGet, api.RequireRole(auth.Device)),
+			"POST /v1/subcategories":   api.Chain(api.WithBody(validate, handlePost), api.RequireUser(auth.User)),
+			"DELETE /v1/subcategories": api.Chain(handleDelete, api.RequireUser(auth.User)),
+		},
+		api.Timing(),
+	).Start()
 }
@@ -2,45 +2,39 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
-	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/history"
 
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
-func handlePost(ctx context.Context, logger zerolog.Logger, body json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
-	var req applingoapi.RequestPostUrlsV1
-	if err := serializer.UnmarshalJSON(body, &req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	if err := validate.ValidateStruct(&req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-
+func handlePost(ctx context.Context, _ zerolog.Logger, req applingoapi.RequestPostUrlsV1, _ openapi.QueryParams, _ api.PathParams) (any, *api.HandleError) {
 	switch req.Operation {
 	case "upload":
 		return handleUpload(ctx, req)
 	case "download":
 		return handleDownload(ctx, req)
 	default:
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: fmt.Errorf("invalid operation")}
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: fmt.Errorf("invalid operation"), Message: "invalid operation"}
 	}
 }
 
 func handleUpload(ctx context.Context, req applingoapi.RequestPostUrlsV1) (any, *api.HandleError) {
 	if api.MustGetMetaData(ctx).IsDevice() || !api.MustGetMetaData(ctx).HasPermissions(auth.User) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions"), Message: "insufficient permissions"}
 	}
 	if req.Identifier == "" {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.New("missing required fields")}
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.New("missing required fields"), Message: "missing required fields"}
 	}
 	url, err := s3Bucket.UploadURL(ctx, req.Identifier, serviceProcessingBucket, "text/csv")
 	if err != nil {
@@ -54,15 +48,15 @@ func handleUpload(ctx context.Context, req applingoapi.RequestPostUrlsV1) (any,
 }
 
 func handleDownload(ctx context.Context, req applingoapi.RequestPostUrlsV1) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).HasPermissions(auth.Device) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
 	if req.Identifier == "" {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.New("missing required fields")}
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.New("missing required fields"), Message: "missing required fields"}
+	}
+	if herr := authorizeVersionDownload(ctx, req.Identifier); herr != nil {
+		return nil, herr
 	}
 	url, err := s3Bucket.DownloadURL(ctx, req.Identifier, serviceDictionaryBucket)
 	if err != nil {
-		return nil, &api.HandleError{Status: http.StatusNotFound, Err: err}
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: err, Message: "file not found"}
 	}
 
 	return openapi.DataResponseUrls(applingoapi.UrlsData{
@@ -70,3 +64,36 @@ func handleDownload(ctx context.Context, req applingoapi.RequestPostUrlsV1) (any
 		ExpiresIn: 15,
 	}), nil
 }
+
+// authorizeVersionDownload applies visibility of the dictionary to its version files:
+// public dictionaries are downloaded by everyone, others only by the owner and managers.
+// Hidden and trashed dictionaries are reported as missing files.
+func authorizeVersionDownload(ctx context.Context, identifier string) *api.HandleError {
+	id, subcategory, _, ok := history.Parse(identifier)
+	if !ok {
+		return nil
+	}
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableName, map[string]types.AttributeValue{
+		"id":          &types.AttributeValueMemberS{Value: id},
+		"subcategory": &types.AttributeValueMemberS{Value: subcategory},
+	})
+	if err != nil {
+		return &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get dictionary")}
+	}
+	if result.Item == nil {
+		return &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary not found"), Message: "file not found"}
+	}
+	var item applingodictionary.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	meta := api.MustGetMetaData(ctx)
+	if item.DeletedAt != 0 {
+		return &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary is in trash"), Message: "file not found"}
+	}
+	if !meta.CanView(item.Owner, applingodictionary.IntToBool(item.IsPublic)) {
+		return &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary is not public"), Message: "file not found"}
+	}
+	return nil
+}
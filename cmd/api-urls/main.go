@@ -6,10 +6,10 @@ import (
 	"runtime/debug"
 
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 	"github.com/Mad-Pixels/applingo-api/pkg/validator"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
@@ -20,6 +20,7 @@ var (
 
 	validate *validator.Validator
 	s3Bucket *cloud.Bucket
+	dbDynamo *cloud.Dynamo
 )
 
 func init() {
@@ -31,17 +32,18 @@ func init() {
 		panic("unable to load AWS SDK config: " + err.Error())
 	}
 	s3Bucket = cloud.NewBucket(cfg)
+	dbDynamo = cloud.NewDynamo(cfg)
 }
 
 func main() {
-	lambda.Start(
-		api.NewLambda(
-			api.Config{
-				EnableRequestLogging: true,
-			},
-			map[string]api.HandleFunc{
-				"POST /v1/urls": handlePost,
-			},
-		).Handle,
-	)
+	api.NewLambda(
+		api.Config{
+			EnableRequestLogging: true,
+			CORS:                 api.DefaultCORSConfig(),
+		},
+		map[string]api.HandleFunc{
+			"POST /v1/urls": api.Chain(api.WithBody(validate, handlePost), api.RequireRole(auth.Device)),
+		},
+		api.Timing(),
+	).Start()
 }
@@ -1,22 +1,167 @@
 package main
 
 import (
-	"strconv"
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
 
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
 )
 
-func handleDeviceAuth(timestamp string, signature string, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
-	if err := authenticator.ValidateDeviceRequest(timestamp, signature); err != nil {
-		log.Error().Err(err).Msg("Device authentication failed")
+// Devices without a key may only register once the migration window is over, the shared token
+// is built into every app and cannot be revoked. Until then old app builds use it on all device routes.
+const (
+	registrationMethod   = "POST"
+	registrationResource = "/v1/devices"
+)
+
+func handleDeviceAuth(ctx context.Context, token auth.DeviceToken, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	identifier := token.KeyID
+	if token.Shared() {
+		identifier = ""
+	}
+
+	contentSHA256, err := validateDeviceToken(ctx, token, req)
+	if err != nil {
+		log.Error().Err(err).Str("key_id", token.KeyID).Bool("request_bound", token.RequestBound()).Msg("Device authentication failed")
 		return generatePolicy("", "Deny", req.MethodArn, nil)
 	}
-	context := map[string]interface{}{
-		"permissions": strconv.Itoa(auth.GetPermissionLevel(auth.Device)),
-		"role":        strconv.Itoa(int(auth.Device)),
-		"kind":        strconv.Itoa(int(auth.HMAC)),
+	context := auth.AuthorizerContext(identifier, auth.Device, auth.HMAC)
+	principal := "device"
+	if identifier != "" {
+		principal = identifier
+	}
+	// the authorizer does not see the body, the service compares its hash with the signed one.
+	if contentSHA256 != "" {
+		context["content_sha256"] = contentSHA256
+	}
+	return generatePolicy(principal, "Allow", req.MethodArn, context)
+}
+
+// validateDeviceToken checks the signature and claims the nonce of request-bound tokens,
+// it returns the signed body hash. Legacy tokens are accepted until DEVICE_LEGACY_UNTIL,
+// request-bound shared tokens are restricted to registration afterwards.
+func validateDeviceToken(ctx context.Context, token auth.DeviceToken, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (string, error) {
+	now := time.Now()
+	if !token.RequestBound() && !legacyAllowed(now) {
+		return "", auth.ErrLegacySignature
+	}
+	if token.RequestBound() && token.Shared() && !sharedTokenAllowed(req, now) {
+		return "", auth.ErrSharedTokenRoute
+	}
+
+	secret := ""
+	if !token.Shared() {
+		var err error
+		if secret, err = deviceSecret(ctx, token.KeyID); err != nil {
+			return "", err
+		}
+	}
+
+	if !token.RequestBound() {
+		if token.Shared() {
+			return "", authenticator.ValidateDeviceRequest(token.Timestamp, token.Signature)
+		}
+		return "", authenticator.ValidateDeviceKeyRequest(secret, token.Timestamp, token.Signature)
+	}
+
+	signed := signedRequest(token, req)
+	var err error
+	if token.Shared() {
+		err = authenticator.ValidateSignedDeviceRequest(signed, token.Signature)
+	} else {
+		err = authenticator.ValidateSignedDeviceKeyRequest(secret, signed, token.Signature)
+	}
+	if err != nil {
+		return "", err
+	}
+	keyID := token.KeyID
+	if token.Shared() {
+		keyID = auth.SharedKeyID
+	}
+	if err = claimNonce(ctx, keyID, token.Nonce, token.Timestamp); err != nil {
+		return "", err
+	}
+	return signed.ContentSHA256, nil
+}
+
+// sharedTokenAllowed reports whether the shared device token may call the route at the moment.
+func sharedTokenAllowed(req events.APIGatewayCustomAuthorizerRequestTypeRequest, now time.Time) bool {
+	if req.HTTPMethod == registrationMethod && req.Resource == registrationResource {
+		return true
+	}
+	return legacyAllowed(now)
+}
+
+// signedRequest builds the request covered by the signature, a request without
+// the body hash header is signed as a request with empty body.
+func signedRequest(token auth.DeviceToken, req events.APIGatewayCustomAuthorizerRequestTypeRequest) auth.SignedRequest {
+	query := req.MultiValueQueryStringParameters
+	if len(query) == 0 && len(req.QueryStringParameters) > 0 {
+		query = make(map[string][]string, len(req.QueryStringParameters))
+		for name, value := range req.QueryStringParameters {
+			query[name] = []string{value}
+		}
+	}
+	contentSHA256 := strings.ToLower(headerValue(req.Headers, auth.HeaderContentSHA256))
+	if contentSHA256 == "" {
+		contentSHA256 = auth.PayloadHash(nil)
+	}
+	return auth.SignedRequest{
+		Method:        req.HTTPMethod,
+		Path:          req.Path,
+		Query:         query,
+		ContentSHA256: contentSHA256,
+		Timestamp:     token.Timestamp,
+		Nonce:         token.Nonce,
+	}
+}
+
+// deviceSecret returns signing key of the registered device, the secret is stored encrypted with the KMS key.
+// Keys are read on every authorizer call, so a revoked key is denied with the next request.
+func deviceSecret(ctx context.Context, keyID string) (string, error) {
+	result, err := dbDynamo.Get(ctx, applingodevice.TableName, map[string]types.AttributeValue{
+		"key_id": &types.AttributeValueMemberS{Value: keyID},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get device key")
+	}
+	if result.Item == nil {
+		return "", auth.ErrDeviceKeyUnknown
+	}
+	var device applingodevice.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &device); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal device key")
+	}
+	if device.RevokedAt != 0 {
+		return "", auth.ErrDeviceKeyRevoked
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(device.SecretCiphertext)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode device secret")
+	}
+	secret, err := kmsKeys.Decrypt(ctx, secretKeyID, ciphertext, auth.DeviceSecretContext(keyID))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt device secret")
+	}
+	return string(secret), nil
+}
+
+func headerValue(headers map[string]string, name string) string {
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
 	}
-	return generatePolicy("device", "Allow", req.MethodArn, context)
+	return ""
 }
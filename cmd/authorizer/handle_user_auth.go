@@ -1,24 +1,52 @@
 package main
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingousertoken"
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
 )
 
-func handleUserAuth(token string, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
-	claims, err := authenticator.ValidateJWTToken(token)
+func handleUserAuth(ctx context.Context, token string, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	claims, err := authenticator.ValidateJWTToken(ctx, token)
+	if err == nil {
+		err = checkSession(ctx, claims.Id)
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("JWT authentication failed")
 		return generatePolicy("", "Deny", req.MethodArn, nil)
 	}
-	context := map[string]interface{}{
-		"identifier":  claims.Identifier,
-		"permissions": strconv.Itoa(auth.GetPermissionLevel(auth.User)),
-		"role":        strconv.Itoa(int(auth.User)),
-		"kind":        strconv.Itoa(int(auth.JWT)),
+	identifier := strconv.Itoa(claims.Identifier)
+	return generatePolicy(identifier, "Allow", req.MethodArn, auth.AuthorizerContext(identifier, claims.Role, auth.JWT))
+}
+
+// checkSession rejects tokens of revoked sessions, tokens issued without session are not accepted.
+// Sessions are read on every authorizer call, so logout takes effect with the next request.
+func checkSession(ctx context.Context, tokenID string) error {
+	if tokenID == "" {
+		return auth.ErrMissingTokenID
+	}
+	result, err := dbDynamo.Get(ctx, applingousertoken.TableName, map[string]types.AttributeValue{
+		"token_id": &types.AttributeValueMemberS{Value: tokenID},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get session")
+	}
+	if result.Item == nil {
+		return auth.ErrTokenRevoked
+	}
+	var session applingousertoken.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Item, &session); err != nil {
+		return errors.Wrap(err, "failed to unmarshal session")
+	}
+	if session.RevokedAt != 0 {
+		return auth.ErrTokenRevoked
 	}
-	return generatePolicy(strconv.Itoa(claims.Identifier), "Allow", req.MethodArn, context)
+	return nil
 }
@@ -4,30 +4,64 @@ import (
 	"context"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 	"github.com/Mad-Pixels/applingo-api/pkg/logger"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/pkg/errors"
 )
 
-const tokenSeparator = ":::"
-
 var (
 	deviceToken = os.Getenv("DEVICE_API_TOKEN")
 	jwtSecret   = os.Getenv("JWT_SECRET")
+	jwtJWKS     = os.Getenv("JWT_JWKS")
+	secretKeyID = os.Getenv("DEVICE_SECRET_KEY_ID")
+	awsRegion   = os.Getenv("AWS_REGION")
 
 	log           = logger.InitLogger()
 	authenticator *auth.Authenticator
+	dbDynamo      *cloud.Dynamo
+	kmsKeys       *cloud.Kms
+
+	// legacyUntil ends the migration window for signatures without request binding,
+	// they are rejected when DEVICE_LEGACY_UNTIL (RFC 3339) is empty or passed.
+	legacyUntil time.Time
 )
 
 func init() {
-	if deviceToken == "" || jwtSecret == "" {
-		log.Fatal().Msg("AUTH_TOKEN and JWT_SECRET environment variables must be set")
+	if deviceToken == "" || (jwtSecret == "" && jwtJWKS == "") {
+		log.Fatal().Msg("AUTH_TOKEN and JWT_SECRET or JWT_JWKS environment variables must be set")
+	}
+	if secretKeyID == "" {
+		log.Fatal().Msg("DEVICE_SECRET_KEY_ID environment variable must be set")
 	}
 	authenticator = auth.NewAuthenticator(deviceToken, jwtSecret)
+	if jwtJWKS != "" {
+		keys, err := auth.NewKeySet(jwtJWKS, auth.DefaultJWKSCacheTTL)
+		if err != nil {
+			log.Fatal().Err(err).Msg("JWT_JWKS must be JWKS JSON, URL or file path")
+		}
+		authenticator.WithKeySet(keys)
+	}
+
+	if until := os.Getenv("DEVICE_LEGACY_UNTIL"); until != "" {
+		var err error
+		if legacyUntil, err = time.Parse(time.RFC3339, until); err != nil {
+			log.Fatal().Err(err).Msg("DEVICE_LEGACY_UNTIL must be RFC 3339 time")
+		}
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Unable to load AWS SDK config")
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+	kmsKeys = cloud.NewKms(cfg)
 }
 
 func generatePolicy(principalID string, effect string, resource string, context map[string]interface{}) (events.APIGatewayCustomAuthorizerResponse, error) {
@@ -58,16 +92,15 @@ func handler(ctx context.Context, req events.APIGatewayCustomAuthorizerRequestTy
 		return generatePolicy("", "Deny", req.MethodArn, nil)
 	}
 
-	parts := strings.Split(authHeader, tokenSeparator)
-	switch len(parts) {
-	case 1:
-		return handleUserAuth(parts[0], req)
-	case 2:
-		return handleDeviceAuth(parts[0], parts[1], req)
-	default:
+	if !strings.Contains(authHeader, auth.TokenSeparator) {
+		return handleUserAuth(ctx, authHeader, req)
+	}
+	token, ok := auth.ParseDeviceToken(authHeader)
+	if !ok {
 		log.Error().Msg("Invalid x-api-auth header format")
 		return generatePolicy("", "Deny", req.MethodArn, nil)
 	}
+	return handleDeviceAuth(ctx, token, req)
 }
 
 func main() {
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevicenonce"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// claimNonce remembers the nonce of the key until the timestamp expires,
+// the conditional write fails for a replayed request. DynamoDB TTL purges old nonces.
+func claimNonce(ctx context.Context, keyID, nonce, timestamp string) error {
+	expiresAt, err := auth.NonceExpiresAt(timestamp)
+	if err != nil {
+		return err
+	}
+	err = dbDynamo.Put(ctx, applingodevicenonce.TableName, map[string]types.AttributeValue{
+		"nonce": &types.AttributeValueMemberS{Value: keyID + "#" + nonce},
+		"ttl":   &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+	}, expression.AttributeNotExists(expression.Name("nonce")))
+	if err != nil {
+		if cloud.IsConditionFailed(err) {
+			return auth.ErrNonceReused
+		}
+		return errors.Wrap(err, "failed to claim nonce")
+	}
+	return nil
+}
+
+// legacyAllowed reports whether signatures without request binding are accepted at the moment.
+func legacyAllowed(now time.Time) bool {
+	return !legacyUntil.IsZero() && now.Before(legacyUntil)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"runtime/debug"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionarysearch"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/search"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/trigger"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var (
+	awsRegion = os.Getenv("AWS_REGION")
+
+	dbDynamo *cloud.Dynamo
+)
+
+func init() {
+	debug.SetGCPercent(500)
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+// handler keeps the search index in sync with the dictionary table:
+// terms of the old image missing in the new one are deleted, terms of the new image are written.
+func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) error {
+	var dynamoRecord events.DynamoDBEventRecord
+	if err := serializer.UnmarshalJSON(record, &dynamoRecord); err != nil {
+		return errors.Wrap(err, "failed to unmarshal DynamoDB record")
+	}
+
+	oldRows, err := indexRows(dynamoRecord.Change.OldImage)
+	if err != nil {
+		return errors.Wrap(err, "failed to build index rows from old image")
+	}
+	newRows, err := indexRows(dynamoRecord.Change.NewImage)
+	if err != nil {
+		return errors.Wrap(err, "failed to build index rows from new image")
+	}
+
+	var (
+		puts    []map[string]types.AttributeValue
+		deletes []map[string]types.AttributeValue
+	)
+	for term, row := range oldRows {
+		if _, ok := newRows[term]; !ok {
+			deletes = append(deletes, rowKey(row))
+		}
+	}
+	for term, row := range newRows {
+		if old, ok := oldRows[term]; ok && old == row {
+			continue
+		}
+		item, err := applingodictionarysearch.PutItem(row)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal index row")
+		}
+		puts = append(puts, item)
+	}
+	if len(puts) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	if err = dbDynamo.BatchWrite(ctx, applingodictionarysearch.TableName, puts, deletes); err != nil {
+		return errors.Wrap(err, "failed to update search index")
+	}
+	log.Debug().
+		Str("event", dynamoRecord.EventName).
+		Int("put", len(puts)).
+		Int("deleted", len(deletes)).
+		Msg("Search index updated")
+	return nil
+}
+
+// indexRows returns index rows of a dictionary image by term, only public dictionaries out of trash are searchable.
+func indexRows(image map[string]events.DynamoDBAttributeValue) (map[string]applingodictionarysearch.SchemaItem, error) {
+	if len(image) == 0 {
+		return nil, nil
+	}
+	var dict applingodictionary.SchemaItem
+	if err := trigger.UnmarshalStreamImage(image, &dict); err != nil {
+		return nil, err
+	}
+	if !applingodictionary.IntToBool(dict.IsPublic) || dict.DeletedAt != 0 || dict.Dictionary == "" {
+		return nil, nil
+	}
+
+	terms := search.Document{
+		Name:        dict.Name,
+		Topic:       dict.Topic,
+		Author:      dict.Author,
+		Description: dict.Description,
+	}.Terms()
+
+	rows := make(map[string]applingodictionarysearch.SchemaItem, len(terms))
+	for token, weight := range terms {
+		term := token + "#" + dict.Id + "#" + dict.Subcategory
+		rows[term] = applingodictionarysearch.SchemaItem{
+			Prefix:      search.Prefix(token),
+			Term:        term,
+			Token:       token,
+			Weight:      weight,
+			Id:          dict.Id,
+			Subcategory: dict.Subcategory,
+			Name:        dict.Name,
+			Author:      dict.Author,
+			Category:    dict.Category,
+			Description: dict.Description,
+			Dictionary:  dict.Dictionary,
+			Topic:       dict.Topic,
+			Level:       dict.Level,
+			Created:     dict.Created,
+			Rating:      dict.Rating,
+			Version:     dict.Version,
+		}
+	}
+	return rows, nil
+}
+
+func rowKey(row applingodictionarysearch.SchemaItem) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"prefix": &types.AttributeValueMemberS{Value: row.Prefix},
+		"term":   &types.AttributeValueMemberS{Value: row.Term},
+	}
+}
+
+func main() {
+	lambda.Start(
+		trigger.NewLambda(
+			// records of the same dictionary must be applied in stream order,
+			// otherwise a late write could bring back rows of a trashed dictionary.
+			trigger.Config{MaxWorkers: 1},
+			handler,
+		).Handle,
+	)
+}
@@ -18,8 +18,9 @@ import (
 )
 
 var (
-	servicePutScvQueueUrl = os.Getenv("SERVICE_PUT_CSV_QUEUE_URL")
-	awsRegion             = os.Getenv("AWS_REGION")
+	servicePutScvQueueUrl    = os.Getenv("SERVICE_PUT_CSV_QUEUE_URL")
+	serviceDeleteCsvQueueUrl = os.Getenv("SERVICE_DELETE_CSV_QUEUE_URL")
+	awsRegion                = os.Getenv("AWS_REGION")
 
 	sqsQueue *cloud.Queue
 )
@@ -39,13 +40,22 @@ func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) er
 	if err := serializer.UnmarshalJSON(record, &dynamoRecord); err != nil {
 		return errors.Wrap(err, "failed to unmarshal DynamoDB record")
 	}
+	if !hasNewFile(dynamoRecord) {
+		log.Debug().Str("event", dynamoRecord.EventName).Msg("Source file is not changed, skip record")
+		return nil
+	}
 	payload, err := serializer.MarshalJSON(dynamoRecord)
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal DynamoDB record")
 	}
 
+	// removed dictionaries go to the cleanup job, others to the CSV processing job.
+	queueURL := servicePutScvQueueUrl
+	if dynamoRecord.EventName == string(events.DynamoDBOperationTypeRemove) {
+		queueURL = serviceDeleteCsvQueueUrl
+	}
 	_, err = sqsQueue.SendMessage(ctx, cloud.SendMessageInput{
-		QueueURL:    servicePutScvQueueUrl,
+		QueueURL:    queueURL,
 		MessageBody: string(payload),
 	})
 	if err != nil {
@@ -54,6 +64,27 @@ func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) er
 	return nil
 }
 
+// hasNewFile reports whether the record needs the cleanup or the CSV processing job:
+// removed and inserted dictionaries and dictionaries with a new source file.
+// Other changes, e.g. votes, reviews and edits, keep the converted file.
+func hasNewFile(record events.DynamoDBEventRecord) bool {
+	switch events.DynamoDBOperationType(record.EventName) {
+	case events.DynamoDBOperationTypeRemove, events.DynamoDBOperationTypeInsert:
+		return true
+	case events.DynamoDBOperationTypeModify:
+		return streamString(record.Change.OldImage, "filename") != streamString(record.Change.NewImage, "filename")
+	default:
+		return false
+	}
+}
+
+func streamString(image map[string]events.DynamoDBAttributeValue, name string) string {
+	if v, ok := image[name]; ok && v.DataType() == events.DataTypeString {
+		return v.String()
+	}
+	return ""
+}
+
 func main() {
 	lambda.Start(
 		trigger.NewLambda(
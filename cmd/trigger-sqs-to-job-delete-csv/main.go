@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionaryversion"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/history"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/trigger"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	deleteAttempts = 3
+	deleteBackoff  = 200 * time.Millisecond
+)
+
+var (
+	serviceDictionaryBucket = os.Getenv("SERVICE_DICTIONARY_BUCKET")
+	serviceProcessingBucket = os.Getenv("SERVICE_PROCESSING_BUCKET")
+	awsRegion               = os.Getenv("AWS_REGION")
+
+	s3Bucket *cloud.Bucket
+	dbDynamo *cloud.Dynamo
+)
+
+func init() {
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	s3Bucket = cloud.NewBucket(cfg)
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+// handler deletes files of the removed dictionary from the processing and dictionary buckets.
+// Missing objects are skipped, so redelivered messages are safe; failed messages are retried by SQS.
+func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) error {
+	var sqsRecord events.SQSMessage
+	if err := serializer.UnmarshalJSON(record, &sqsRecord); err != nil {
+		return errors.Wrap(err, "failed to unmarshal SQS record")
+	}
+	var dynamoDBEvent events.DynamoDBEventRecord
+	if err := serializer.UnmarshalJSON([]byte(sqsRecord.Body), &dynamoDBEvent); err != nil {
+		return errors.Wrap(err, "failed to unmarshal DynamoDB event from SQS message body")
+	}
+	if dynamoDBEvent.EventName != string(events.DynamoDBOperationTypeRemove) {
+		log.Warn().Str("event", dynamoDBEvent.EventName).Msg("Skip non REMOVE event")
+		return nil
+	}
+
+	var dict applingodictionary.SchemaItem
+	if err := trigger.UnmarshalStreamImage(dynamoDBEvent.Change.OldImage, &dict); err != nil {
+		return errors.Wrap(err, "failed to read removed dictionary")
+	}
+	// versions belong to the id and subcategory, a moved dictionary starts its own history.
+	if err := deleteVersions(ctx, log, dict); err != nil {
+		return err
+	}
+	if dict.Filename == "" {
+		log.Warn().Str("id", dict.Id).Msg("Removed dictionary has no file")
+		return nil
+	}
+
+	// a dictionary moved to another subcategory is removed and inserted with the same file.
+	inUse, err := fileInUse(ctx, dict)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		log.Info().Str("id", dict.Id).Str("filename", dict.Filename).Msg("File is used by another dictionary, skip cleanup")
+		return nil
+	}
+
+	objects := []struct{ key, bucket string }{
+		{dict.Filename, serviceProcessingBucket},
+		{dict.Filename, serviceDictionaryBucket},
+	}
+	if dict.Dictionary != "" && dict.Dictionary != dict.Filename {
+		objects = append(objects, struct{ key, bucket string }{dict.Dictionary, serviceDictionaryBucket})
+	}
+	for _, object := range objects {
+		if err = deleteObject(ctx, object.key, object.bucket); err != nil {
+			return err
+		}
+	}
+	log.Info().Str("id", dict.Id).Str("filename", dict.Filename).Msg("Dictionary files deleted")
+	return nil
+}
+
+// fileInUse reports whether a dictionary with the same id still references the file.
+func fileInUse(ctx context.Context, dict applingodictionary.SchemaItem) (bool, error) {
+	queryInput, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+		KeyCondition:     expression.Key("id").Equal(expression.Value(dict.Id)),
+		ProjectionFields: []string{"id", "subcategory", "filename"},
+		Limit:            10,
+	})
+	if err != nil {
+		return false, err
+	}
+	result, err := dbDynamo.Query(ctx, applingodictionary.TableName, queryInput)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to query dictionaries by id")
+	}
+	for _, item := range result.Items {
+		var other applingodictionary.SchemaItem
+		if err = attributevalue.UnmarshalMap(item, &other); err != nil {
+			return false, errors.Wrap(err, "failed to unmarshal dictionary")
+		}
+		if other.Filename == dict.Filename {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deleteVersions deletes files and records of all versions of the removed dictionary.
+func deleteVersions(ctx context.Context, log zerolog.Logger, dict applingodictionary.SchemaItem) error {
+	key := history.Key(dict.Id, dict.Subcategory)
+	queryInput := cloud.QueryInput{
+		KeyCondition:     expression.Key("dictionary_key").Equal(expression.Value(key)),
+		ProjectionFields: []string{"dictionary_key", "number", "dictionary"},
+		ScanForward:      true,
+	}
+
+	var deleted int
+	for {
+		dynamoQueryInput, err := dbDynamo.BuildQueryInput(queryInput)
+		if err != nil {
+			return err
+		}
+		result, err := dbDynamo.Query(ctx, applingodictionaryversion.TableName, dynamoQueryInput)
+		if err != nil {
+			return errors.Wrap(err, "failed to query dictionary versions")
+		}
+
+		keys := make([]map[string]types.AttributeValue, 0, len(result.Items))
+		for _, item := range result.Items {
+			var version applingodictionaryversion.SchemaItem
+			if err = attributevalue.UnmarshalMap(item, &version); err != nil {
+				return errors.Wrap(err, "failed to unmarshal dictionary version")
+			}
+			if err = deleteObject(ctx, version.Dictionary, serviceDictionaryBucket); err != nil {
+				return err
+			}
+			keys = append(keys, map[string]types.AttributeValue{
+				"dictionary_key": item["dictionary_key"],
+				"number":         item["number"],
+			})
+		}
+		if err = dbDynamo.BatchWrite(ctx, applingodictionaryversion.TableName, nil, keys); err != nil {
+			return errors.Wrap(err, "failed to delete dictionary versions")
+		}
+		deleted += len(keys)
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		queryInput.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+	if deleted > 0 {
+		log.Info().Str("id", dict.Id).Str("subcategory", dict.Subcategory).Int("versions", deleted).Msg("Dictionary versions deleted")
+	}
+	return nil
+}
+
+// deleteObject deletes the object with retries, a missing object counts as deleted.
+func deleteObject(ctx context.Context, key, bucket string) error {
+	var err error
+	for attempt := 1; attempt <= deleteAttempts; attempt++ {
+		err = s3Bucket.Delete(ctx, key, bucket)
+		if err == nil || errors.Is(err, cloud.ErrBucketObjectNotFound) {
+			return nil
+		}
+		if attempt == deleteAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(deleteBackoff * time.Duration(attempt)):
+		}
+	}
+	return errors.Wrapf(err, "failed to delete %s from bucket %s", key, bucket)
+}
+
+func main() {
+	lambda.Start(
+		trigger.NewLambda(
+			trigger.Config{MaxWorkers: 4},
+			handler,
+		).Handle,
+	)
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
 	"github.com/Mad-Pixels/applingo-api/pkg/trigger"
@@ -23,8 +24,7 @@ import (
 )
 
 const (
-	dictionaryFilenameKey = "filename"
-	maxSampleSize         = 1024 * 1024 // 1MB
+	maxSampleSize = 1024 * 1024 // 1MB
 )
 
 var (
@@ -33,6 +33,7 @@ var (
 	awsRegion               = os.Getenv("AWS_REGION")
 
 	s3Bucket *cloud.Bucket
+	dbDynamo *cloud.Dynamo
 )
 
 func init() {
@@ -41,6 +42,7 @@ func init() {
 		panic("unable to load AWS SDK config: " + err.Error())
 	}
 	s3Bucket = cloud.NewBucket(cfg)
+	dbDynamo = cloud.NewDynamo(cfg)
 }
 
 func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) error {
@@ -53,17 +55,27 @@ func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) er
 		return errors.Wrap(err, "failed to unmarshal DynamoDB event from SQS message body")
 	}
 
-	bucketKey, ok := dynamoDBEvent.Change.NewImage[dictionaryFilenameKey]
-	if !ok {
-		return errors.New("'dictionaryFilenameKey' not found in DynamoDB event")
+	var dict applingodictionary.SchemaItem
+	if err := trigger.UnmarshalStreamImage(dynamoDBEvent.Change.NewImage, &dict); err != nil {
+		return errors.Wrap(err, "failed to read dictionary from DynamoDB event")
 	}
-	if bucketKey.DataType() != events.DataTypeString {
-		return errors.New("'dictionaryFilenameKey' is not a string in DynamoDB event")
+	if dict.Filename == "" {
+		return errors.New("'filename' not found in DynamoDB event")
 	}
-	return processFile(ctx, log, bucketKey.String())
+	if dict.DeletedAt != 0 {
+		log.Debug().Str("id", dict.Id).Msg("Dictionary is in trash, skip processing")
+		return nil
+	}
+
+	csvData, err := processFile(ctx, log, dict.Filename)
+	if err != nil {
+		return err
+	}
+	return saveVersion(ctx, log, dict, []byte(csvData))
 }
 
-func processFile(ctx context.Context, log zerolog.Logger, filename string) error {
+// processFile downloads the uploaded file and converts it to CSV.
+func processFile(ctx context.Context, log zerolog.Logger, filename string) (string, error) {
 	pr, pw := io.Pipe()
 	var wg sync.WaitGroup
 	var processErr error
@@ -89,14 +101,9 @@ func processFile(ctx context.Context, log zerolog.Logger, filename string) error
 	wg.Wait()
 
 	if processErr != nil {
-		return processErr
+		return "", processErr
 	}
-
-	if err := s3Bucket.Put(ctx, filename, serviceDictionaryBucket, strings.NewReader(csvData.String()), "text/csv"); err != nil {
-		return errors.Wrapf(err, "failed to upload file %s to bucket %s", filename, serviceDictionaryBucket)
-	}
-
-	return nil
+	return csvData.String(), nil
 }
 
 func convertToCSV(_ zerolog.Logger, r io.Reader, w io.Writer) error {
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionaryversion"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/history"
+	"github.com/Mad-Pixels/applingo-api/pkg/trash"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// saveVersion stores converted file as the next immutable version and makes it current,
+// unless the dictionary is pinned to another version. The same content as the latest version is skipped,
+// so stream events of unrelated changes do not produce new versions.
+func saveVersion(ctx context.Context, log zerolog.Logger, dict applingodictionary.SchemaItem, data []byte) error {
+	key := history.Key(dict.Id, dict.Subcategory)
+	checksum := history.Checksum(data)
+
+	latest, err := latestVersion(ctx, key)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.Checksum == checksum {
+		log.Debug().Str("id", dict.Id).Int("version", latest.Number).Msg("File is not changed, skip version")
+		return nil
+	}
+	number := 1
+	if latest != nil {
+		number = latest.Number + 1
+	}
+
+	version := applingodictionaryversion.SchemaItem{
+		DictionaryKey: key,
+		Number:        number,
+		Id:            dict.Id,
+		Subcategory:   dict.Subcategory,
+		Dictionary:    history.Object(dict.Id, dict.Subcategory, number),
+		Filename:      dict.Filename,
+		Checksum:      checksum,
+		Size:          len(data),
+		Created:       int(time.Now().Unix()),
+	}
+	item, err := applingodictionaryversion.PutItem(version)
+	if err != nil {
+		return err
+	}
+	// the record claims the number first, a concurrent conversion fails here and is retried by SQS.
+	if err = dbDynamo.Put(
+		ctx,
+		applingodictionaryversion.TableName,
+		item,
+		expression.AttributeNotExists(expression.Name("number")),
+	); err != nil {
+		return errors.Wrapf(err, "failed to save version %d", number)
+	}
+	if err = s3Bucket.Put(ctx, version.Dictionary, serviceDictionaryBucket, bytes.NewReader(data), "text/csv"); err != nil {
+		if delErr := dbDynamo.Delete(ctx, applingodictionaryversion.TableName, versionKey(key, number)); delErr != nil {
+			log.Error().Err(delErr).Str("id", dict.Id).Int("version", number).Msg("Failed to release version")
+		}
+		return errors.Wrapf(err, "failed to upload file %s to bucket %s", version.Dictionary, serviceDictionaryBucket)
+	}
+
+	if dict.PinnedVersion != 0 {
+		log.Info().Str("id", dict.Id).Int("version", number).Int("pinned", dict.PinnedVersion).Msg("Dictionary is pinned, version saved")
+		return nil
+	}
+	update := expression.
+		Set(expression.Name("dictionary"), expression.Value(version.Dictionary)).
+		Set(expression.Name("current_version"), expression.Value(number))
+	condition := expression.AttributeExists(expression.Name("id")).
+		And(trash.NotDeleted()).
+		And(expression.Or(
+			expression.AttributeNotExists(expression.Name("pinned_version")),
+			expression.Name("pinned_version").Equal(expression.Value(0)),
+		))
+
+	if err = dbDynamo.Update(ctx, applingodictionary.TableName, map[string]types.AttributeValue{
+		"id":          &types.AttributeValueMemberS{Value: dict.Id},
+		"subcategory": &types.AttributeValueMemberS{Value: dict.Subcategory},
+	}, update, condition); err != nil {
+		if cloud.IsConditionFailed(err) {
+			log.Info().Str("id", dict.Id).Int("version", number).Msg("Dictionary was pinned or removed, version saved")
+			return nil
+		}
+		return errors.Wrap(err, "failed to set current version")
+	}
+	log.Info().Str("id", dict.Id).Str("subcategory", dict.Subcategory).Int("version", number).Msg("Dictionary version saved")
+	return nil
+}
+
+// latestVersion returns the version with the highest number, nil when the dictionary has no versions.
+func latestVersion(ctx context.Context, key string) (*applingodictionaryversion.SchemaItem, error) {
+	queryInput, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+		KeyCondition: expression.Key("dictionary_key").Equal(expression.Value(key)),
+		Limit:        1,
+		ScanForward:  false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := dbDynamo.Query(ctx, applingodictionaryversion.TableName, queryInput)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query latest version")
+	}
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	var version applingodictionaryversion.SchemaItem
+	if err = attributevalue.UnmarshalMap(result.Items[0], &version); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal version")
+	}
+	return &version, nil
+}
+
+func versionKey(key string, number int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"dictionary_key": &types.AttributeValueMemberS{Value: key},
+		"number":         &types.AttributeValueMemberN{Value: strconv.Itoa(number)},
+	}
+}
@@ -0,0 +1,170 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+
+	enumsMu sync.RWMutex
+	enums   = make(map[reflect.Type][]string)
+)
+
+func init() {
+	RegisterEnum(applingoapi.Language)
+	RegisterEnum(applingoapi.Date, applingoapi.Rating)
+	RegisterEnum(applingoapi.Front, applingoapi.Back)
+	RegisterEnum(applingoapi.Download, applingoapi.Upload)
+	RegisterEnum(applingoapi.TrashDictionary, applingoapi.TrashSubcategory)
+	RegisterEnum(applingoapi.StatusDraft, applingoapi.StatusPending, applingoapi.StatusApproved, applingoapi.StatusRejected)
+}
+
+// RegisterEnum sets allowed values of a generated enum type, Decode rejects other values.
+func RegisterEnum[T ~string](values ...T) {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, string(v))
+	}
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	enums[reflect.TypeOf(values).Elem()] = names
+}
+
+// Decode fills a generated applingoapi.*Params struct from query parameters using "form" tags.
+// Supported fields are strings, enums, bools, numbers, time.Duration, time.Time,
+// slices of them and pointers to any of those. Missing parameters leave fields untouched,
+// invalid values are reported as *ParamError.
+func (q QueryParams) Decode(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode destination must be a non-nil pointer to struct, got %T", dst)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		values, ok := q.raw[key]
+		if !ok {
+			continue
+		}
+		if err := setField(rv.Field(i), key, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setField(v reflect.Value, key string, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setField(elem.Elem(), key, values); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice {
+		var items []string
+		for _, value := range values {
+			items = append(items, strings.Split(value, ",")...)
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setScalar(slice.Index(i), key, item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return setScalar(v, key, values[len(values)-1])
+}
+
+func setScalar(v reflect.Value, key, value string) error {
+	invalid := func(reason string) error {
+		return &ParamError{Key: key, Value: value, Rule: RuleType, Reason: reason}
+	}
+
+	switch v.Type() {
+	case durationType:
+		d, reason := parseDuration(value)
+		if reason != "" {
+			return invalid(reason)
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, reason := parseTime(value)
+		if reason != "" {
+			return invalid(reason)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		enumsMu.RLock()
+		allowed, isEnum := enums[v.Type()]
+		enumsMu.RUnlock()
+		if isEnum && !contains(allowed, value) {
+			return &ParamError{Key: key, Value: value, Rule: RuleOneOf, Reason: "expected one of: " + strings.Join(allowed, ", ")}
+		}
+		v.SetString(value)
+	case reflect.Bool:
+		b, reason := parseBool(value)
+		if reason != "" {
+			return invalid(reason)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return invalid("expected integer")
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return invalid("expected non-negative integer")
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return invalid("expected number")
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s for query parameter '%s'", v.Type(), key)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,10 @@ var (
 		return applingoapi.ResponseMessage{Data: applingoapi.MessageData{Message: message}}
 	}
 
+	DataResponseError = func(data applingoapi.ErrorData) applingoapi.ResponseError {
+		return applingoapi.ResponseError{Error: data}
+	}
+
 	DataResponseUrls = func(data applingoapi.UrlsData) applingoapi.ResponsePostUrlsV1 {
 		return applingoapi.ResponsePostUrlsV1{Data: data}
 	}
@@ -23,6 +27,26 @@ var (
 		return applingoapi.ResponseGetDictionariesV1{Data: data}
 	}
 
+	DataResponseDictionariesSearch = func(data applingoapi.DictionariesSearchData) applingoapi.ResponseGetDictionariesSearchV1 {
+		return applingoapi.ResponseGetDictionariesSearchV1{Data: data}
+	}
+
+	DataResponseTrash = func(data applingoapi.TrashData) applingoapi.ResponseGetTrashV1 {
+		return applingoapi.ResponseGetTrashV1{Data: data}
+	}
+
+	DataResponseVersions = func(data applingoapi.VersionsData) applingoapi.ResponseGetVersionsV1 {
+		return applingoapi.ResponseGetVersionsV1{Data: data}
+	}
+
+	DataResponseAuth = func(data applingoapi.AuthTokensData) applingoapi.ResponsePostAuthV1 {
+		return applingoapi.ResponsePostAuthV1{Data: data}
+	}
+
+	DataResponseDevices = func(data applingoapi.DeviceCredentialsData) applingoapi.ResponsePostDevicesV1 {
+		return applingoapi.ResponsePostDevicesV1{Data: data}
+	}
+
 	DataResponseLevels = func(data applingoapi.LevelsData) applingoapi.ResponseGetLevelsV1 {
 		return applingoapi.ResponseGetLevelsV1{Data: data}
 	}
@@ -5,8 +5,39 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// ErrParamNotFound is returned by getters when the query parameter is missing.
+var ErrParamNotFound = errors.New("query parameter not found")
+
+// Rules reported by ParamError, named after the validator tags with the same meaning.
+const (
+	RuleRequired = "required"
+	RuleType     = "type"
+	RuleOneOf    = "oneof"
+)
+
+// ParamError describes a query parameter which is missing or cannot be parsed.
+type ParamError struct {
+	Key    string
+	Value  string
+	Rule   string
+	Reason string
+	Err    error
+}
+
+func (e *ParamError) Error() string {
+	if e.Rule == RuleRequired {
+		return fmt.Sprintf("query parameter '%s' is required", e.Key)
+	}
+	return fmt.Sprintf("invalid value '%s' for query parameter '%s': %s", e.Value, e.Key, e.Reason)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
 func ParseEnumParam[T ~string](value *string, validValues map[T]struct{}) (*T, error) {
 	if value == nil {
 		return nil, nil
@@ -18,27 +49,65 @@ func ParseEnumParam[T ~string](value *string, validValues map[T]struct{}) (*T, e
 	return nil, errors.New("invalid enum value")
 }
 
+// GetEnum returns query parameter value if it is one of the values, nil if parameter is missing.
+func GetEnum[T ~string](q QueryParams, key string, values ...T) (*T, error) {
+	v, ok := q.last(key)
+	if !ok {
+		return nil, nil
+	}
+	for _, allowed := range values {
+		if string(allowed) == v {
+			enumVal := allowed
+			return &enumVal, nil
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for _, allowed := range values {
+		names = append(names, string(allowed))
+	}
+	return nil, &ParamError{Key: key, Value: v, Rule: RuleOneOf, Reason: "expected one of: " + strings.Join(names, ", ")}
+}
+
+// QueryParams keeps all values of query parameters, single value getters use the last one.
 type QueryParams struct {
-	raw map[string]string
+	raw map[string][]string
 }
 
-func NewQueryParams(params map[string]string) QueryParams {
-	if params == nil {
-		params = make(map[string]string)
+// NewQueryParams builds QueryParams from multi-value parameters, params are used for keys missing there.
+func NewQueryParams(params map[string]string, multiValueParams map[string][]string) QueryParams {
+	raw := make(map[string][]string, len(multiValueParams)+len(params))
+	for k, v := range multiValueParams {
+		if len(v) > 0 {
+			raw[k] = v
+		}
 	}
-	return QueryParams{raw: params}
+	for k, v := range params {
+		if _, ok := raw[k]; !ok {
+			raw[k] = []string{v}
+		}
+	}
+	return QueryParams{raw: raw}
 }
 
-func (q QueryParams) GetString(key string) (string, error) {
+func (q QueryParams) last(key string) (string, bool) {
 	v, ok := q.raw[key]
 	if !ok {
-		return "", fmt.Errorf("key '%s' not found", key)
+		return "", false
+	}
+	return v[len(v)-1], true
+}
+
+func (q QueryParams) GetString(key string) (string, error) {
+	v, ok := q.last(key)
+	if !ok {
+		return "", &ParamError{Key: key, Rule: RuleRequired, Err: ErrParamNotFound}
 	}
 	return v, nil
 }
 
 func (q QueryParams) GetStringDefault(key, defaultValue string) string {
-	if v, ok := q.raw[key]; ok {
+	if v, ok := q.last(key); ok {
 		return v
 	}
 	return defaultValue
@@ -53,59 +122,84 @@ func (q QueryParams) GetStringPtr(key string) *string {
 }
 
 func (q QueryParams) GetBool(key string) (bool, error) {
-	v, ok := q.raw[key]
-	if !ok {
-		return false, fmt.Errorf("key '%s' not found", key)
-	}
-	return strconv.ParseBool(v)
+	return getParsed(q, key, parseBool)
 }
 
-func (q QueryParams) GetBoolDefault(key string, defaultValue bool) bool {
-	v, err := q.GetBool(key)
-	if err != nil {
-		return defaultValue
-	}
-	return v
+// GetBoolDefault returns defaultValue if parameter is missing and an error if it is not a bool.
+func (q QueryParams) GetBoolDefault(key string, defaultValue bool) (bool, error) {
+	return getParsedDefault(q, key, defaultValue, parseBool)
 }
 
-func (q QueryParams) GetBoolPtr(key string) *bool {
-	if !q.Has(key) {
-		return nil
-	}
-	v := q.GetBoolDefault(key, false)
-	return &v
+// GetBoolPtr returns nil if parameter is missing and an error if it is not a bool.
+func (q QueryParams) GetBoolPtr(key string) (*bool, error) {
+	return getParsedPtr(q, key, parseBool)
 }
 
 func (q QueryParams) GetInt(key string) (int, error) {
-	v, ok := q.raw[key]
-	if !ok {
-		return 0, fmt.Errorf("key '%s' not found", key)
-	}
-	return strconv.Atoi(v)
+	return getParsed(q, key, parseInt)
 }
 
-func (q QueryParams) GetIntDefault(key string, defaultValue int) int {
-	v, err := q.GetInt(key)
-	if err != nil {
-		return defaultValue
-	}
-	return v
+// GetIntDefault returns defaultValue if parameter is missing and an error if it is not an integer.
+func (q QueryParams) GetIntDefault(key string, defaultValue int) (int, error) {
+	return getParsedDefault(q, key, defaultValue, parseInt)
 }
 
-func (q QueryParams) GetIntPtr(key string) *int {
-	if !q.Has(key) {
-		return nil
-	}
-	v := q.GetIntDefault(key, 0)
-	return &v
+// GetIntPtr returns nil if parameter is missing and an error if it is not an integer.
+func (q QueryParams) GetIntPtr(key string) (*int, error) {
+	return getParsedPtr(q, key, parseInt)
 }
 
-func (q QueryParams) GetSlice(key string) ([]string, error) {
+func (q QueryParams) GetFloat(key string) (float64, error) {
+	return getParsed(q, key, parseFloat)
+}
+
+// GetFloatDefault returns defaultValue if parameter is missing and an error if it is not a number.
+func (q QueryParams) GetFloatDefault(key string, defaultValue float64) (float64, error) {
+	return getParsedDefault(q, key, defaultValue, parseFloat)
+}
+
+// GetDuration parses Go duration format, e.g. "90s" or "1h30m".
+func (q QueryParams) GetDuration(key string) (time.Duration, error) {
+	return getParsed(q, key, parseDuration)
+}
+
+// GetDurationDefault returns defaultValue if parameter is missing and an error if it is not a duration.
+func (q QueryParams) GetDurationDefault(key string, defaultValue time.Duration) (time.Duration, error) {
+	return getParsedDefault(q, key, defaultValue, parseDuration)
+}
+
+// GetTime parses RFC 3339 timestamps or unix seconds.
+func (q QueryParams) GetTime(key string) (time.Time, error) {
+	return getParsed(q, key, parseTime)
+}
+
+// GetTimePtr returns nil if parameter is missing and an error if it is not a timestamp.
+func (q QueryParams) GetTimePtr(key string) (*time.Time, error) {
+	return getParsedPtr(q, key, parseTime)
+}
+
+// GetAll returns every value of repeated parameter, e.g. "?level=A1&level=B2".
+func (q QueryParams) GetAll(key string) ([]string, error) {
 	v, ok := q.raw[key]
 	if !ok {
-		return nil, fmt.Errorf("key '%s' not found", key)
+		return nil, &ParamError{Key: key, Rule: RuleRequired, Err: ErrParamNotFound}
 	}
-	return strings.Split(v, ","), nil
+	return v, nil
+}
+
+// GetSlice returns values of repeated parameter, each value is also split by comma,
+// so "?level=A1&level=B2" and "?level=A1,B2" give the same result.
+func (q QueryParams) GetSlice(key string) ([]string, error) {
+	values, err := q.GetAll(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, v := range values {
+		result = append(result, strings.Split(v, ",")...)
+	}
+	return result, nil
 }
 
 func (q QueryParams) GetSlicePtr(key string) *[]string {
@@ -124,6 +218,93 @@ func (q QueryParams) Has(key string) bool {
 	return ok
 }
 
+// Raw returns the last value of every parameter.
 func (q QueryParams) Raw() map[string]string {
+	raw := make(map[string]string, len(q.raw))
+	for k := range q.raw {
+		raw[k], _ = q.last(k)
+	}
+	return raw
+}
+
+// RawValues returns all values of every parameter.
+func (q QueryParams) RawValues() map[string][]string {
 	return q.raw
 }
+
+// parser converts raw value, reason is returned for invalid values.
+type parser[T any] func(value string) (T, string)
+
+func getParsed[T any](q QueryParams, key string, parse parser[T]) (T, error) {
+	var zero T
+
+	v, ok := q.last(key)
+	if !ok {
+		return zero, &ParamError{Key: key, Rule: RuleRequired, Err: ErrParamNotFound}
+	}
+	result, reason := parse(v)
+	if reason != "" {
+		return zero, &ParamError{Key: key, Value: v, Rule: RuleType, Reason: reason}
+	}
+	return result, nil
+}
+
+func getParsedDefault[T any](q QueryParams, key string, defaultValue T, parse parser[T]) (T, error) {
+	if !q.Has(key) {
+		return defaultValue, nil
+	}
+	return getParsed(q, key, parse)
+}
+
+func getParsedPtr[T any](q QueryParams, key string, parse parser[T]) (*T, error) {
+	if !q.Has(key) {
+		return nil, nil
+	}
+	v, err := getParsed(q, key, parse)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func parseBool(v string) (bool, string) {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, "expected boolean"
+	}
+	return b, ""
+}
+
+func parseInt(v string) (int, string) {
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, "expected integer"
+	}
+	return i, ""
+}
+
+func parseFloat(v string) (float64, string) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, "expected number"
+	}
+	return f, ""
+}
+
+func parseDuration(v string) (time.Duration, string) {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, "expected duration, e.g. '90s'"
+	}
+	return d, ""
+}
+
+func parseTime(v string) (time.Time, string) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, ""
+	}
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), ""
+	}
+	return time.Time{}, "expected RFC 3339 timestamp or unix seconds"
+}
@@ -2,9 +2,12 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"runtime/debug"
+	"strings"
 
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
@@ -15,93 +18,187 @@ import (
 	"github.com/rs/zerolog"
 )
 
-type HandleFunc func(context.Context, zerolog.Logger, json.RawMessage, openapi.QueryParams) (any, *HandleError)
+type HandleFunc func(context.Context, zerolog.Logger, json.RawMessage, openapi.QueryParams, PathParams) (any, *HandleError)
 
 type API struct {
-	cfg      Config
-	log      zerolog.Logger
-	handlers map[string]HandleFunc
+	cfg    Config
+	log    zerolog.Logger
+	router *router
 }
 
-func NewLambda(cfg Config, handlers map[string]HandleFunc) *API {
+// NewLambda creates API instance, middlewares are applied to every handler
+// in the given order with the first one being the outermost.
+func NewLambda(cfg Config, handlers map[string]HandleFunc, middlewares ...Middleware) *API {
 	if handlers == nil {
 		panic("handlers map cannot be nil")
 	}
+	router := newRouter(handlers, middlewares...)
+	router.markPublic(cfg.PublicRoutes)
+
 	return &API{
-		cfg:      cfg,
-		handlers: handlers,
-		log:      logger.InitLogger(),
+		cfg:    cfg,
+		router: router,
+		log:    logger.InitLogger(),
 	}
 }
 
+// Handle processes API Gateway REST API proxy event.
 func (a *API) Handle(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	opKey := fmt.Sprintf("%s %s", req.HTTPMethod, req.Path)
+	return a.serve(ctx, requestFromV1(req))
+}
 
-	mCtx, err := ctxWithAuth(ctx, req)
-	if err != nil {
+// serve processes normalized request, the response is built in REST API shape and converted by callers.
+// Every response carries X-Request-Id header with the request correlation ID and CORS headers,
+// handler panics are recovered into 500 responses.
+func (a *API) serve(ctx context.Context, req *request) (resp events.APIGatewayProxyResponse, err error) {
+	requestID, lambdaID := requestIDs(ctx, req)
+	log := requestLogger(a.log, requestID, lambdaID)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().
+				Str("httpMethod", req.method).
+				Str("path", req.path).
+				Str("panic", fmt.Sprint(r)).
+				Bytes("stack", debug.Stack()).
+				Msg("Recovered from panic")
+
+			resp, err = errorResponse(&HandleError{Status: http.StatusInternalServerError}, requestID, nil)
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]string)
+		}
+		resp.Headers[HeaderRequestID] = requestID
+		a.cfg.CORS.apply(resp.Headers, headerValue(req.headers, headerOrigin))
+
+		if cErr := a.cfg.Compression.apply(&resp, headerValue(req.headers, headerAcceptEncoding)); cErr != nil {
+			log.Warn().Err(cErr).Msg("Failed to compress response, sending it uncompressed")
+		}
+	}()
+	return a.handle(context.WithValue(ctx, requestIDKey, requestID), log, requestID, req)
+}
+
+func (a *API) handle(ctx context.Context, log zerolog.Logger, requestID string, req *request) (events.APIGatewayProxyResponse, error) {
+	opKey := fmt.Sprintf("%s %s", req.method, req.path)
+
+	m, ok := a.router.lookup(req.method, req.path)
+	if !ok {
 		if a.cfg.EnableRequestLogging {
-			a.logError(req, opKey, err)
+			logError(log, req, opKey, errors.New("Unknown operation"))
 		}
+		return errorResponse(&HandleError{Status: http.StatusNotFound}, requestID, nil)
+	}
+	if isPreflight(req.method, req.headers) {
 		return gatewayResponse(
-			http.StatusUnauthorized,
-			openapi.DataResponseMessage(http.StatusText(http.StatusUnauthorized)),
+			http.StatusNoContent,
 			nil,
+			a.cfg.CORS.preflight(headerValue(req.headers, headerOrigin), headerValue(req.headers, headerRequestHeaders), m.allowed),
 		)
 	}
-	if a.cfg.EnableRequestLogging {
-		a.logRequest(mCtx, req)
+	allowHeader := map[string]string{"Allow": strings.Join(m.allowed, ", ")}
+	if m.route == nil {
+		if m.implicit {
+			return gatewayResponse(http.StatusNoContent, nil, allowHeader)
+		}
+		if a.cfg.EnableRequestLogging {
+			logError(log, req, opKey, errors.New("Method not allowed"))
+		}
+		return errorResponse(&HandleError{Status: http.StatusMethodNotAllowed}, requestID, allowHeader)
 	}
 
-	handler, ok := a.handlers[opKey]
-	if !ok {
+	authorizer := req.authorizer
+	if len(authorizer) == 0 && a.cfg.Authorizer != nil {
+		var err error
+		if authorizer, err = a.cfg.Authorizer(ctx, req.headers); err != nil {
+			if a.cfg.EnableRequestLogging {
+				logError(log, req, opKey, errors.Wrap(err, "authorization failed"))
+			}
+			return errorResponse(&HandleError{Status: http.StatusUnauthorized}, requestID, nil)
+		}
+	}
+	mCtx, err := ctxWithAuth(ctx, authorizer)
+	if err != nil && len(authorizer) == 0 && m.route.public {
+		mCtx, err = ctxWithGuest(ctx), nil
+	}
+	if err != nil {
 		if a.cfg.EnableRequestLogging {
-			a.logError(req, opKey, errors.New("Unknown operation"))
+			logError(log, req, opKey, err)
 		}
-		return gatewayResponse(
-			http.StatusNotFound,
-			openapi.DataResponseMessage(http.StatusText(http.StatusNotFound)),
-			nil,
-		)
+		return errorResponse(&HandleError{Status: http.StatusUnauthorized}, requestID, nil)
+	}
+	if a.cfg.EnableRequestLogging {
+		logRequest(mCtx, log, req)
 	}
 
-	result, handleError := handler(
+	body := []byte(req.body)
+	if req.isBase64 {
+		if body, err = base64.StdEncoding.DecodeString(req.body); err != nil {
+			return errorResponse(&HandleError{Status: http.StatusBadRequest, Err: errors.Wrap(err, "invalid base64 body"), Message: "malformed request body"}, requestID, nil)
+		}
+	}
+	if err = verifyContentHash(authorizer, body); err != nil {
+		if a.cfg.EnableRequestLogging {
+			logError(log, req, opKey, err)
+		}
+		return errorResponse(&HandleError{Status: http.StatusUnauthorized}, requestID, nil)
+	}
+	result, handleError := m.route.handler(
 		mCtx,
-		a.log,
-		json.RawMessage(req.Body),
-		openapi.NewQueryParams(req.QueryStringParameters),
+		log,
+		json.RawMessage(body),
+		openapi.NewQueryParams(req.query, req.multiQuery),
+		m.params,
 	)
 	if handleError != nil {
 		if a.cfg.EnableRequestLogging {
-			a.logError(req, opKey, handleError.Err)
+			logError(log, req, opKey, handleError.Err)
 		}
-		return gatewayResponse(
-			handleError.Status,
-			openapi.DataResponseMessage(http.StatusText(handleError.Status)),
-			nil,
-		)
+		return errorResponse(handleError, requestID, nil)
 	}
 
 	var status int
 	switch {
-	case req.HTTPMethod == "POST":
+	case req.method == "POST":
 		status = http.StatusCreated
-	case req.HTTPMethod == "DELETE":
+	case req.method == "DELETE":
 		status = http.StatusNoContent
 	default:
 		status = http.StatusOK
 	}
-	return gatewayResponse(status, result, nil)
+	var headers map[string]string
+	if r, ok := result.(*Response); ok {
+		if r.Status != 0 {
+			status = r.Status
+		}
+		headers = make(map[string]string, len(r.Headers))
+		for k, v := range r.Headers {
+			headers[k] = v
+		}
+		result = r.Body
+	}
+
+	resp, err := gatewayResponse(status, result, headers)
+	if err != nil {
+		return resp, err
+	}
+	if nm, ok := notModified(req, resp); ok {
+		return nm, nil
+	}
+	if req.method == "HEAD" {
+		resp.Body = ""
+	}
+	return resp, nil
 }
 
-func (a *API) logRequest(ctx context.Context, req events.APIGatewayProxyRequest) {
+func logRequest(ctx context.Context, log zerolog.Logger, req *request) {
 	meta := MustGetMetaData(ctx)
 
-	event := a.log.Info().
-		Str("path", req.Path).
-		Str("httpMethod", req.HTTPMethod).
-		Str("domainName", req.RequestContext.DomainName).
-		Str("sourceIp", req.RequestContext.Identity.SourceIP).
-		Str("userAgent", req.RequestContext.Identity.UserAgent).
+	event := log.Info().
+		Str("path", req.path).
+		Str("httpMethod", req.method).
+		Str("domainName", req.domainName).
+		Str("sourceIp", req.sourceIP).
+		Str("userAgent", req.userAgent).
 		Str("auth_type", meta.kind.String()).
 		Str("role", auth.RoleNames[meta.level])
 	if meta.IsUser() {
@@ -110,11 +207,11 @@ func (a *API) logRequest(ctx context.Context, req events.APIGatewayProxyRequest)
 	event.Msg("Received API Gateway event")
 }
 
-func (a *API) logError(req events.APIGatewayProxyRequest, opKey string, err error) {
-	a.log.Error().
-		Str("httpMethod", req.HTTPMethod).
+func logError(log zerolog.Logger, req *request, opKey string, err error) {
+	log.Error().
+		Str("httpMethod", req.method).
 		Str("operationKey", opKey).
-		Str("path", req.Path).
+		Str("path", req.path).
 		Err(err).
 		Msg("Error handling request")
 }
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	headerAcceptEncoding  = "Accept-Encoding"
+	headerContentEncoding = "Content-Encoding"
+
+	encodingGzip = "gzip"
+
+	defaultCompressionMinSize = 1024
+)
+
+// CompressionConfig enables gzip compression of response bodies negotiated from Accept-Encoding.
+// Compressed bodies are sent base64 encoded, API Gateway must have binary media types configured.
+type CompressionConfig struct {
+	// MinSize is the minimal body size in bytes to compress, 1024 when zero.
+	MinSize int
+	// Level is gzip compression level, gzip.DefaultCompression when zero.
+	Level int
+}
+
+// apply compresses response body in place when client accepts gzip and the body is large enough.
+func (c *CompressionConfig) apply(resp *events.APIGatewayProxyResponse, acceptEncoding string) error {
+	if c == nil || resp.IsBase64Encoded || resp.Headers[headerContentEncoding] != "" {
+		return nil
+	}
+	if resp.Body != "" || resp.StatusCode == http.StatusNotModified {
+		addVary(resp.Headers, headerAcceptEncoding)
+	}
+
+	if !acceptsEncoding(acceptEncoding, encodingGzip) {
+		return nil
+	}
+	// the ETag depends on Accept-Encoding only, so 304 responses, which have no body,
+	// carry the same validator as the 200 response to the same request.
+	if etag := resp.Headers[headerETag]; etag != "" {
+		// strong validators must differ between representations.
+		resp.Headers[headerETag] = strings.TrimSuffix(etag, `"`) + "-" + encodingGzip + `"`
+	}
+
+	minSize := c.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+	if len(resp.Body) < minSize {
+		return nil
+	}
+
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return err
+	}
+	if _, err = zw.Write([]byte(resp.Body)); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+
+	resp.Body = base64.StdEncoding.EncodeToString(buf.Bytes())
+	resp.IsBase64Encoded = true
+	resp.Headers[headerContentEncoding] = encodingGzip
+	return nil
+}
+
+// acceptsEncoding reports whether Accept-Encoding header allows the encoding, "q=0" excludes it.
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.TrimSpace(name)
+		if !strings.EqualFold(name, encoding) && name != "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.EqualFold(k, "q") {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		return q > 0
+	}
+	return false
+}
+
+// trimEncodingSuffix removes encoding suffix added to ETag of responses to clients accepting gzip.
+func trimEncodingSuffix(etag string) string {
+	return strings.Replace(etag, "-"+encodingGzip+`"`, `"`, 1)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	headerETag            = "ETag"
+	headerCacheControl    = "Cache-Control"
+	headerLastModified    = "Last-Modified"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+)
+
+// strongETag returns quoted ETag value computed over serialized body.
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified converts successful GET response into 304 when request preconditions match,
+// validators and cache headers are kept, the body is dropped.
+func notModified(req *request, resp events.APIGatewayProxyResponse) (events.APIGatewayProxyResponse, bool) {
+	if resp.StatusCode != http.StatusOK || (req.method != http.MethodGet && req.method != http.MethodHead) {
+		return resp, false
+	}
+
+	// If-Modified-Since is ignored when If-None-Match is present, RFC 9110 13.1.3.
+	if inm := headerValue(req.headers, headerIfNoneMatch); inm != "" {
+		if !etagMatch(inm, resp.Headers[headerETag]) {
+			return resp, false
+		}
+	} else {
+		ims, err := http.ParseTime(headerValue(req.headers, headerIfModifiedSince))
+		if err != nil {
+			return resp, false
+		}
+		lm, err := http.ParseTime(resp.Headers[headerLastModified])
+		if err != nil || lm.After(ims) {
+			return resp, false
+		}
+	}
+
+	headers := make(map[string]string, len(resp.Headers))
+	for k, v := range resp.Headers {
+		if k != "Content-Type" {
+			headers[k] = v
+		}
+	}
+	return events.APIGatewayProxyResponse{StatusCode: http.StatusNotModified, Headers: headers}, true
+}
+
+// etagMatch compares If-None-Match list with etag using weak comparison.
+func etagMatch(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if trimEncodingSuffix(strings.TrimPrefix(strings.TrimSpace(candidate), "W/")) == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestNotModifiedETagVariants(t *testing.T) {
+	compression := &CompressionConfig{MinSize: 16}
+	body := strings.Repeat("a", 64)
+	etag := strongETag([]byte(body))
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		body           string
+		wantETag       string
+	}{
+		{name: "gzip", acceptEncoding: "gzip", body: body, wantETag: strings.TrimSuffix(etag, `"`) + `-gzip"`},
+		{name: "gzip small body", acceptEncoding: "gzip", body: "a", wantETag: strings.TrimSuffix(strongETag([]byte("a")), `"`) + `-gzip"`},
+		{name: "identity", acceptEncoding: "", body: body, wantETag: etag},
+		{name: "gzip refused", acceptEncoding: "gzip;q=0", body: body, wantETag: etag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send := func(ifNoneMatch string) events.APIGatewayProxyResponse {
+				req := &request{method: http.MethodGet, headers: map[string]string{
+					headerAcceptEncoding: tt.acceptEncoding,
+					headerIfNoneMatch:    ifNoneMatch,
+				}}
+				resp := events.APIGatewayProxyResponse{
+					StatusCode: http.StatusOK,
+					Headers:    map[string]string{headerETag: strongETag([]byte(tt.body))},
+					Body:       tt.body,
+				}
+				if nm, ok := notModified(req, resp); ok {
+					resp = nm
+				}
+				if err := compression.apply(&resp, tt.acceptEncoding); err != nil {
+					t.Fatalf("apply() error = %v", err)
+				}
+				return resp
+			}
+
+			full := send("")
+			if full.StatusCode != http.StatusOK || full.Headers[headerETag] != tt.wantETag {
+				t.Fatalf("200 response = %d with ETag %s, want %s", full.StatusCode, full.Headers[headerETag], tt.wantETag)
+			}
+			cached := send(full.Headers[headerETag])
+			if cached.StatusCode != http.StatusNotModified {
+				t.Fatalf("revalidation status = %d, want 304", cached.StatusCode)
+			}
+			if cached.Headers[headerETag] != full.Headers[headerETag] {
+				t.Errorf("304 ETag = %s, 200 ETag = %s", cached.Headers[headerETag], full.Headers[headerETag])
+			}
+		})
+	}
+}
@@ -2,4 +2,17 @@ package api
 
 type Config struct {
 	EnableRequestLogging bool
+
+	// CORS is applied to every response and answers preflight requests, disabled when nil.
+	CORS *CORSConfig
+
+	// Compression gzips large response bodies when client accepts it, disabled when nil.
+	Compression *CompressionConfig
+
+	// Authorizer is used for events without authorizer context, e.g. Lambda Function URLs.
+	Authorizer RequestAuthorizer
+
+	// PublicRoutes lists handler keys, e.g. "GET /v1/auth/jwks", which are served to guests
+	// when the request has no authorizer context. Their spec operations must have `security: []`.
+	PublicRoutes []string
 }
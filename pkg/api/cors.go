@@ -0,0 +1,158 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+)
+
+const (
+	headerOrigin           = "Origin"
+	headerVary             = "Vary"
+	headerAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAllowMethods     = "Access-Control-Allow-Methods"
+	headerAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAllowCredentials = "Access-Control-Allow-Credentials"
+	headerExposeHeaders    = "Access-Control-Expose-Headers"
+	headerMaxAge           = "Access-Control-Max-Age"
+	headerRequestMethod    = "Access-Control-Request-Method"
+	headerRequestHeaders   = "Access-Control-Request-Headers"
+	corsWildcard           = "*"
+)
+
+// CORSConfig describes cross-origin policy applied to every API response.
+type CORSConfig struct {
+	// AllowOrigins lists allowed origins, "*" allows any origin.
+	AllowOrigins []string
+	// AllowMethods lists methods sent in preflight responses,
+	// methods registered for the requested path are used when empty.
+	AllowMethods []string
+	// AllowHeaders lists request headers sent in preflight responses,
+	// headers requested by the browser are echoed when empty.
+	AllowHeaders []string
+	// ExposeHeaders lists response headers readable by the browser, X-Request-Id is always exposed.
+	ExposeHeaders []string
+	// AllowCredentials allows cookies and auth headers, the request origin is echoed instead of "*".
+	AllowCredentials bool
+	// MaxAge is how long preflight result can be cached, zero disables the header.
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig allows any origin with the headers used by service clients.
+func DefaultCORSConfig() *CORSConfig {
+	return &CORSConfig{
+		AllowOrigins: []string{corsWildcard},
+		AllowHeaders: []string{"Content-Type", "Authorization", "x-api-auth", auth.HeaderContentSHA256, auth.HeaderTimestamp, auth.HeaderSignature},
+		MaxAge:       time.Hour,
+	}
+}
+
+// originAllowed reports whether the origin matches the policy.
+func (c *CORSConfig) originAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, o := range c.AllowOrigins {
+		if o == corsWildcard || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply sets CORS headers for the request origin, only Vary is set for disallowed origins.
+func (c *CORSConfig) apply(headers map[string]string, origin string) {
+	if c == nil {
+		return
+	}
+	// responses of policies echoing the origin differ per origin, including requests without
+	// or with a disallowed one, so caches must not share them.
+	echoOrigin := c.AllowCredentials || !c.wildcard()
+	if echoOrigin {
+		addVary(headers, headerOrigin)
+	}
+	if !c.originAllowed(origin) {
+		return
+	}
+
+	if echoOrigin {
+		headers[headerAllowOrigin] = origin
+	} else {
+		headers[headerAllowOrigin] = corsWildcard
+	}
+	if c.AllowCredentials {
+		headers[headerAllowCredentials] = "true"
+	}
+	headers[headerExposeHeaders] = strings.Join(append([]string{HeaderRequestID}, c.ExposeHeaders...), ",")
+}
+
+// preflight returns headers for a preflight request, allowed lists methods registered for the path.
+// Origin headers are added by apply as for any other response.
+func (c *CORSConfig) preflight(origin, requestHeaders string, allowed []string) map[string]string {
+	headers := map[string]string{"Allow": strings.Join(allowed, ", ")}
+	if c == nil || !c.originAllowed(origin) {
+		return headers
+	}
+
+	methods := c.AllowMethods
+	if len(methods) == 0 {
+		methods = allowed
+	}
+	headers[headerAllowMethods] = strings.Join(methods, ",")
+
+	if len(c.AllowHeaders) > 0 {
+		headers[headerAllowHeaders] = strings.Join(c.AllowHeaders, ",")
+	} else if requestHeaders != "" {
+		headers[headerAllowHeaders] = requestHeaders
+		addVary(headers, headerRequestHeaders)
+	}
+	if c.MaxAge > 0 {
+		headers[headerMaxAge] = strconv.Itoa(int(c.MaxAge.Seconds()))
+	}
+	return headers
+}
+
+func (c *CORSConfig) wildcard() bool {
+	for _, o := range c.AllowOrigins {
+		if o == corsWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// addVary appends the header name to Vary unless it is listed already or Vary is "*".
+func addVary(headers map[string]string, name string) {
+	v := headers[headerVary]
+	if v == "" {
+		headers[headerVary] = name
+		return
+	}
+	for _, listed := range strings.Split(v, ",") {
+		if listed = strings.TrimSpace(listed); listed == corsWildcard || strings.EqualFold(listed, name) {
+			return
+		}
+	}
+	headers[headerVary] = v + ", " + name
+}
+
+// isPreflight reports whether request is a CORS preflight request.
+func isPreflight(method string, headers map[string]string) bool {
+	return method == http.MethodOptions && headerValue(headers, headerRequestMethod) != ""
+}
+
+// headerValue returns header value ignoring name case, API Gateway keeps names as sent by the client.
+func headerValue(headers map[string]string, name string) string {
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
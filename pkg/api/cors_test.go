@@ -0,0 +1,44 @@
+package api
+
+import "testing"
+
+func TestCORSApply(t *testing.T) {
+	listed := &CORSConfig{AllowOrigins: []string{"https://app.example"}}
+	credentials := &CORSConfig{AllowOrigins: []string{corsWildcard}, AllowCredentials: true}
+	wildcard := &CORSConfig{AllowOrigins: []string{corsWildcard}}
+
+	tests := []struct {
+		name       string
+		cfg        *CORSConfig
+		origin     string
+		vary       string // Vary set before apply
+		wantOrigin string
+		wantVary   string
+	}{
+		{name: "listed origin", cfg: listed, origin: "https://app.example", wantOrigin: "https://app.example", wantVary: "Origin"},
+		{name: "disallowed origin", cfg: listed, origin: "https://evil.example", wantVary: "Origin"},
+		{name: "no origin", cfg: listed, wantVary: "Origin"},
+		{name: "credentials echo origin", cfg: credentials, origin: "https://any.example", wantOrigin: "https://any.example", wantVary: "Origin"},
+		{name: "wildcard", cfg: wildcard, origin: "https://any.example", wantOrigin: corsWildcard},
+		{name: "wildcard no origin", cfg: wildcard},
+		{name: "origin listed already", cfg: listed, origin: "https://app.example", vary: "Accept-Encoding, origin", wantOrigin: "https://app.example", wantVary: "Accept-Encoding, origin"},
+		{name: "other vary kept", cfg: listed, vary: "Accept-Encoding", wantVary: "Accept-Encoding, Origin"},
+		{name: "vary any", cfg: listed, vary: "*", wantVary: "*"},
+		{name: "disabled", origin: "https://app.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.vary != "" {
+				headers[headerVary] = tt.vary
+			}
+			tt.cfg.apply(headers, tt.origin)
+			if headers[headerAllowOrigin] != tt.wantOrigin {
+				t.Errorf("%s = %q, want %q", headerAllowOrigin, headers[headerAllowOrigin], tt.wantOrigin)
+			}
+			if headers[headerVary] != tt.wantVary {
+				t.Errorf("Vary = %q, want %q", headers[headerVary], tt.wantVary)
+			}
+		})
+	}
+}
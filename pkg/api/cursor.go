@@ -0,0 +1,167 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	cursorVersion   = "v1"
+	cursorSeparator = "."
+	cursorMaxLen    = 2048
+)
+
+var (
+	// ErrInvalidCursor is returned for malformed, tampered or unknown version cursors.
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+	// ErrCursorMismatch is returned for cursors issued for another index or filter.
+	ErrCursorMismatch = errors.New("pagination cursor does not match the request")
+)
+
+// CursorScope binds cursor to the result set it was issued for.
+type CursorScope struct {
+	// Index is DynamoDB index name, empty for table queries.
+	Index string
+	// Filter holds request parameters which select the result set, e.g. level or subcategory.
+	Filter map[string]string
+}
+
+// fingerprint returns stable hash of the scope.
+func (s CursorScope) fingerprint() string {
+	keys := make([]string, 0, len(s.Filter))
+	for k := range s.Filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	h.Write([]byte(s.Index))
+	for _, k := range keys {
+		h.Write([]byte{0})
+		h.Write([]byte(k + "=" + s.Filter[k]))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// Paginator issues and verifies opaque pagination cursors wrapping DynamoDB LastEvaluatedKey.
+// Cursor format is "v1.<payload>.<signature>", both parts are base64url encoded.
+type Paginator struct {
+	secret []byte
+}
+
+// NewPaginator creates Paginator signing cursors with the secret.
+func NewPaginator(secret string) *Paginator {
+	if secret == "" {
+		panic("pagination secret cannot be empty")
+	}
+	return &Paginator{secret: []byte(secret)}
+}
+
+type cursorPayload struct {
+	Scope string                    `json:"s"`
+	Key   map[string]cursorKeyValue `json:"k"`
+}
+
+// cursorKeyValue is JSON form of key attribute, keys may only contain S, N and B attributes.
+type cursorKeyValue struct {
+	S *string `json:"s,omitempty"`
+	N *string `json:"n,omitempty"`
+	B []byte  `json:"b,omitempty"`
+}
+
+// Encode returns cursor for LastEvaluatedKey, empty string for the last page.
+func (p *Paginator) Encode(scope CursorScope, key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	payload := cursorPayload{
+		Scope: scope.fingerprint(),
+		Key:   make(map[string]cursorKeyValue, len(key)),
+	}
+	for name, av := range key {
+		switch v := av.(type) {
+		case *types.AttributeValueMemberS:
+			payload.Key[name] = cursorKeyValue{S: &v.Value}
+		case *types.AttributeValueMemberN:
+			payload.Key[name] = cursorKeyValue{N: &v.Value}
+		case *types.AttributeValueMemberB:
+			payload.Key[name] = cursorKeyValue{B: v.Value}
+		default:
+			return "", errors.Errorf("unsupported key attribute type %T for '%s'", av, name)
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal cursor")
+	}
+	body := cursorVersion + cursorSeparator + base64.RawURLEncoding.EncodeToString(data)
+	return body + cursorSeparator + p.sign(body), nil
+}
+
+// Decode verifies cursor and returns ExclusiveStartKey for the scope.
+func (p *Paginator) Decode(scope CursorScope, cursor string) (map[string]types.AttributeValue, error) {
+	if len(cursor) > cursorMaxLen {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(cursor, cursorSeparator)
+	if len(parts) != 3 || parts[0] != cursorVersion {
+		return nil, ErrInvalidCursor
+	}
+	body := parts[0] + cursorSeparator + parts[1]
+	if !hmac.Equal([]byte(p.sign(body)), []byte(parts[2])) {
+		return nil, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err = json.Unmarshal(data, &payload); err != nil || len(payload.Key) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	if payload.Scope != scope.fingerprint() {
+		return nil, ErrCursorMismatch
+	}
+
+	key := make(map[string]types.AttributeValue, len(payload.Key))
+	for name, v := range payload.Key {
+		switch {
+		case v.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *v.S}
+		case v.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *v.N}
+		case v.B != nil:
+			key[name] = &types.AttributeValueMemberB{Value: v.B}
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return key, nil
+}
+
+// CursorError converts Decode error into 400 response.
+func CursorError(err error) *HandleError {
+	message := ErrInvalidCursor.Error()
+	if errors.Is(err, ErrCursorMismatch) {
+		message = ErrCursorMismatch.Error()
+	}
+	return &HandleError{Status: http.StatusBadRequest, Err: err, Message: message}
+}
+
+func (p *Paginator) sign(body string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,6 +1,128 @@
 package api
 
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+)
+
+// ErrorCode is a stable machine-readable error code sent to clients.
+type ErrorCode string
+
+const (
+	ErrCodeBadRequest       ErrorCode = "bad_request"
+	ErrCodeValidation       ErrorCode = "validation_failed"
+	ErrCodeUnauthorized     ErrorCode = "unauthorized"
+	ErrCodeForbidden        ErrorCode = "forbidden"
+	ErrCodeNotFound         ErrorCode = "not_found"
+	ErrCodeMethodNotAllowed ErrorCode = "method_not_allowed"
+	ErrCodeConflict         ErrorCode = "conflict"
+	ErrCodeInternal         ErrorCode = "internal_error"
+)
+
+var statusErrorCodes = map[int]ErrorCode{
+	http.StatusBadRequest:       ErrCodeBadRequest,
+	http.StatusUnauthorized:     ErrCodeUnauthorized,
+	http.StatusForbidden:        ErrCodeForbidden,
+	http.StatusNotFound:         ErrCodeNotFound,
+	http.StatusMethodNotAllowed: ErrCodeMethodNotAllowed,
+	http.StatusConflict:         ErrCodeConflict,
+}
+
+const validationMessage = "request validation failed"
+
+// HandleError is returned by handlers to produce an error response.
+// Err is only logged, Code, Message and Details are sent to the client;
+// for 5xx statuses only Code is sent and the message is the status text.
 type HandleError struct {
-	Err    error
-	Status int
+	Err     error
+	Status  int
+	Code    ErrorCode
+	Message string
+	Details []validator.FieldError
+}
+
+// response builds the error envelope sent to the client.
+func (e *HandleError) response(requestID string) applingoapi.ResponseError {
+	var (
+		code    = e.Code
+		message = e.Message
+		details = e.Details
+	)
+
+	if e.Status >= http.StatusInternalServerError {
+		if code == "" {
+			code = ErrCodeInternal
+		}
+		return openapi.DataResponseError(applingoapi.ErrorData{
+			Code:      applingoapi.BaseErrorCodeEnum(code),
+			Message:   http.StatusText(e.Status),
+			RequestId: optionalString(requestID),
+		})
+	}
+
+	if len(details) == 0 {
+		fields, ok := paramErrorFields(e.Err)
+		if !ok {
+			fields, ok = validator.ErrorFields(e.Err)
+		}
+		if ok {
+			details = fields
+			if code == "" {
+				code = ErrCodeValidation
+			}
+			if message == "" {
+				message = validationMessage
+			}
+		}
+	}
+	if code == "" {
+		if code = statusErrorCodes[e.Status]; code == "" {
+			code = ErrCodeBadRequest
+		}
+	}
+	if message == "" {
+		message = http.StatusText(e.Status)
+	}
+
+	data := applingoapi.ErrorData{
+		Code:      applingoapi.BaseErrorCodeEnum(code),
+		Message:   message,
+		RequestId: optionalString(requestID),
+	}
+	if len(details) > 0 {
+		items := make([]applingoapi.ErrorDetail, 0, len(details))
+		for _, d := range details {
+			items = append(items, applingoapi.ErrorDetail{
+				Field:   d.Field,
+				Rule:    d.Rule,
+				Message: d.Message,
+			})
+		}
+		data.Details = &items
+	}
+	return openapi.DataResponseError(data)
+}
+
+// paramErrorFields converts query parameter decoding error into validation details.
+func paramErrorFields(err error) ([]validator.FieldError, bool) {
+	var paramErr *openapi.ParamError
+	if !errors.As(err, &paramErr) {
+		return nil, false
+	}
+	return []validator.FieldError{{
+		Field:   paramErr.Key,
+		Rule:    paramErr.Rule,
+		Message: paramErr.Error(),
+	}}, true
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
 }
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvLocalAddr switches Start into local HTTP mode when set, e.g. ":8080".
+	EnvLocalAddr = "LOCAL_HTTP_ADDR"
+
+	HeaderLocalKind       = "x-local-kind"
+	HeaderLocalRole       = "x-local-role"
+	HeaderLocalIdentifier = "x-local-identifier"
+
+	localStage      = "local"
+	localMaxBodyLen = 10 * 1024 * 1024 // API Gateway payload limit
+)
+
+var errLocalBodyTooLarge = errors.New("request body exceeds API Gateway payload limit")
+
+// Authorizer builds the authorizer context which API Gateway would attach to the request.
+type Authorizer func(r *http.Request) (map[string]interface{}, error)
+
+// StaticAuthorizer returns an Authorizer which attaches the same identity to every request.
+func StaticAuthorizer(kind auth.Kind, role auth.Role, identifier string) Authorizer {
+	return func(_ *http.Request) (map[string]interface{}, error) {
+		return auth.AuthorizerContext(identifier, role, kind), nil
+	}
+}
+
+// HeaderAuthorizer reads the identity from x-local-* headers and defaults to a device.
+// Kind and role accept either names ("jwt", "manager") or their numeric values.
+func HeaderAuthorizer(r *http.Request) (map[string]interface{}, error) {
+	kind, role := auth.HMAC, auth.Device
+
+	if v := r.Header.Get(HeaderLocalKind); v != "" {
+		k, ok := auth.ParseKind(v)
+		if !ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || !auth.KindIsValid(auth.Kind(n)) {
+				return nil, errors.Errorf("invalid '%s' header: %s", HeaderLocalKind, v)
+			}
+			k = auth.Kind(n)
+		}
+		kind = k
+	}
+	if v := r.Header.Get(HeaderLocalRole); v != "" {
+		rl, ok := auth.ParseRole(v)
+		if !ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, errors.Errorf("invalid '%s' header: %s", HeaderLocalRole, v)
+			}
+			rl = auth.Role(n)
+		}
+		role = rl
+	}
+	return auth.AuthorizerContext(r.Header.Get(HeaderLocalIdentifier), role, kind), nil
+}
+
+// Start runs the API as a Lambda handler, or as a local HTTP server when EnvLocalAddr is set.
+func (a *API) Start() {
+	addr := os.Getenv(EnvLocalAddr)
+	if addr == "" {
+		lambda.Start(a.HandleEvent)
+		return
+	}
+
+	a.log.Info().Str("addr", addr).Msg("Starting local HTTP server")
+	if err := http.ListenAndServe(addr, a.HTTPHandler(HeaderAuthorizer)); err != nil {
+		a.log.Fatal().Err(err).Msg("Local HTTP server stopped")
+	}
+}
+
+// HTTPHandler serves the API handlers through net/http by converting every request
+// into an API Gateway proxy event and writing the proxy response back.
+func (a *API) HTTPHandler(authorizer Authorizer) http.Handler {
+	if authorizer == nil {
+		panic("authorizer cannot be nil")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authContext, err := authorizer(r)
+		if err != nil {
+			a.log.Error().Err(err).Str("path", r.URL.Path).Msg("Local authorization failed")
+			resp, _ := errorResponse(&HandleError{Status: http.StatusUnauthorized}, "", nil)
+			writeProxyResponse(w, resp)
+			return
+		}
+
+		req, err := proxyRequestFromHTTP(r, authContext)
+		if err != nil {
+			a.log.Error().Err(err).Str("path", r.URL.Path).Msg("Failed to convert local request")
+			status := http.StatusBadRequest
+			if errors.Is(err, errLocalBodyTooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			resp, _ := errorResponse(&HandleError{Status: status, Err: err}, "", nil)
+			writeProxyResponse(w, resp)
+			return
+		}
+
+		resp, err := a.Handle(r.Context(), req)
+		if err != nil {
+			a.log.Error().Err(err).Str("path", r.URL.Path).Msg("Handler returned an error")
+		}
+		writeProxyResponse(w, resp)
+	})
+}
+
+func proxyRequestFromHTTP(r *http.Request, authContext map[string]interface{}) (events.APIGatewayProxyRequest, error) {
+	// one byte over the limit tells a too large body from a body of exactly the limit.
+	body, err := io.ReadAll(io.LimitReader(r.Body, localMaxBodyLen+1))
+	if err != nil {
+		return events.APIGatewayProxyRequest{}, errors.Wrap(err, "failed to read request body")
+	}
+	if len(body) > localMaxBodyLen {
+		return events.APIGatewayProxyRequest{}, errLocalBodyTooLarge
+	}
+
+	headers := make(map[string]string, len(r.Header))
+	multiHeaders := make(map[string][]string, len(r.Header))
+	for k, v := range r.Header {
+		headers[k] = v[len(v)-1]
+		multiHeaders[k] = v
+	}
+	if r.Host != "" {
+		headers["Host"] = r.Host
+		multiHeaders["Host"] = []string{r.Host}
+	}
+
+	var (
+		query      = r.URL.Query()
+		params     map[string]string
+		multiParam map[string][]string
+	)
+	if len(query) > 0 {
+		params = make(map[string]string, len(query))
+		multiParam = make(map[string][]string, len(query))
+		for k, v := range query {
+			params[k] = v[len(v)-1]
+			multiParam[k] = v
+		}
+	}
+
+	sourceIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		sourceIP = r.RemoteAddr
+	}
+	return events.APIGatewayProxyRequest{
+		Resource:                        r.URL.Path,
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               multiHeaders,
+		QueryStringParameters:           params,
+		MultiValueQueryStringParameters: multiParam,
+		Body:                            string(body),
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID:    newRequestID(),
+			Stage:        localStage,
+			DomainName:   r.Host,
+			HTTPMethod:   r.Method,
+			Path:         r.URL.Path,
+			ResourcePath: r.URL.Path,
+			Authorizer:   authContext,
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  sourceIP,
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}, nil
+}
+
+func writeProxyResponse(w http.ResponseWriter, resp events.APIGatewayProxyResponse) {
+	for k, values := range resp.MultiValueHeaders {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	for k, v := range resp.Headers {
+		w.Header().Set(k, v)
+	}
+
+	body := []byte(resp.Body)
+	if resp.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(resp.Body)
+		if err == nil {
+			body = decoded
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(body)
+}
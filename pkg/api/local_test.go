@@ -0,0 +1,32 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxyRequestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr error
+	}{
+		{name: "empty", size: 0},
+		{name: "at limit", size: localMaxBodyLen},
+		{name: "over limit", size: localMaxBodyLen + 1, wantErr: errLocalBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/v1/dictionaries", bytes.NewReader(make([]byte, tt.size)))
+			req, err := proxyRequestFromHTTP(r, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("proxyRequestFromHTTP() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(req.Body) != tt.size {
+				t.Errorf("body = %d bytes, want %d", len(req.Body), tt.size)
+			}
+		})
+	}
+}
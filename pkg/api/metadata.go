@@ -2,17 +2,22 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"strconv"
 
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/pkg/errors"
 )
 
 type contextKey string
 
-const metaDataKey contextKey = "metadata"
+const (
+	metaDataKey contextKey = "metadata"
+
+	// unknownIdentifier identifies guests and devices signing with the shared token.
+	unknownIdentifier = "ufo"
+)
 
 func GetMetaData(ctx context.Context) (MetaData, bool) {
 	meta, ok := ctx.Value(metaDataKey).(MetaData)
@@ -28,9 +33,10 @@ func MustGetMetaData(ctx context.Context) MetaData {
 }
 
 type MetaData struct {
-	level      auth.Role
-	kind       auth.Kind
-	identifier string
+	level       auth.Role
+	kind        auth.Kind
+	identifier  string
+	permissions int
 }
 
 func (m MetaData) HasPermissions(requiredLevel auth.Role) bool {
@@ -41,16 +47,48 @@ func (m MetaData) GetRole() auth.Role {
 	return m.level
 }
 
+// GetPermissions returns permission level of the caller role, see auth.RolePermissions.
+func (m MetaData) GetPermissions() int {
+	return m.permissions
+}
+
+// GetIdentifier returns caller identifier from the authorizer: user id, device key id,
+// or "ufo" for devices registering with the shared token.
+func (m MetaData) GetIdentifier() string {
+	return m.identifier
+}
+
+// HasIdentifier reports whether the caller is a user or a device with its own key.
+func (m MetaData) HasIdentifier() bool {
+	return m.identifier != unknownIdentifier
+}
+
 func (m MetaData) IsDevice() bool {
 	return m.kind == auth.HMAC && m.level == auth.Device
 }
 
 func (m MetaData) IsUser() bool {
-	return m.kind == auth.JWT && m.level != auth.Device
+	return m.kind == auth.JWT && m.level >= auth.User
+}
+
+// IsOwner reports whether the caller is the user with the owner id, items without owner have none.
+func (m MetaData) IsOwner(owner string) bool {
+	return m.IsUser() && owner != "" && owner == m.identifier
+}
+
+// CanManage reports whether the caller owns the item or has the role managing items of other users.
+func (m MetaData) CanManage(owner string) bool {
+	return m.IsOwner(owner) || m.HasPermissions(auth.Manager)
+}
+
+// CanView reports whether the caller sees the item, public items are seen by everyone,
+// others only by callers who can manage them.
+func (m MetaData) CanView(owner string, public bool) bool {
+	return public || m.CanManage(owner)
 }
 
-func ctxWithAuth(ctx context.Context, req events.APIGatewayProxyRequest) (context.Context, error) {
-	kindStr, ok := req.RequestContext.Authorizer["kind"].(string)
+func ctxWithAuth(ctx context.Context, authorizer map[string]interface{}) (context.Context, error) {
+	kindStr, ok := authorizer["kind"].(string)
 	if !ok {
 		return ctx, errors.New("missing 'kind' in context")
 	}
@@ -63,7 +101,7 @@ func ctxWithAuth(ctx context.Context, req events.APIGatewayProxyRequest) (contex
 		return ctx, errors.New("invalid 'kind' in context")
 	}
 
-	roleStr, ok := req.RequestContext.Authorizer["role"].(string)
+	roleStr, ok := authorizer["role"].(string)
 	if !ok {
 		return ctx, errors.New("missing 'role' in context")
 	}
@@ -72,17 +110,54 @@ func ctxWithAuth(ctx context.Context, req events.APIGatewayProxyRequest) (contex
 		return ctx, errors.Wrap(err, "invalid 'role' format")
 	}
 	level := auth.Role(rawRole)
+	if !auth.RoleIsValid(level) {
+		return ctx, errors.New("invalid 'role' in context")
+	}
+	if kind == auth.JWT && !auth.UserRoleIsValid(level) {
+		return ctx, errors.New("jwt must claim 'user' role or above")
+	}
+	if kind == auth.HMAC && level != auth.Device {
+		return ctx, errors.New("hmac must have 'device' role")
+	}
 
-	identifier := "ufo"
-	if kind == auth.JWT {
-		if id, ok := req.RequestContext.Authorizer["identifier"].(string); ok {
-			identifier = id
+	permissions := auth.GetPermissionLevel(level)
+	if permStr, ok := authorizer["permissions"].(string); ok {
+		if permissions, err = strconv.Atoi(permStr); err != nil {
+			return ctx, errors.Wrap(err, "invalid 'permissions' format")
 		}
 	}
 
+	identifier := unknownIdentifier
+	if id, ok := authorizer["identifier"].(string); ok && id != "" {
+		identifier = id
+	}
+
 	return context.WithValue(ctx, metaDataKey, MetaData{
-		level:      level,
-		kind:       kind,
-		identifier: identifier,
+		level:       level,
+		kind:        kind,
+		identifier:  identifier,
+		permissions: permissions,
 	}), nil
 }
+
+// ctxWithGuest attaches guest metadata for public routes, guests are neither devices nor users.
+func ctxWithGuest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, metaDataKey, MetaData{
+		level:       auth.Guest,
+		identifier:  unknownIdentifier,
+		permissions: auth.GetPermissionLevel(auth.Guest),
+	})
+}
+
+// verifyContentHash compares the body with the hash covered by the device signature,
+// the authorizer does not receive bodies and passes the signed hash in its context.
+func verifyContentHash(authorizer map[string]interface{}, body []byte) error {
+	signed, ok := authorizer["content_sha256"].(string)
+	if !ok || signed == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(auth.PayloadHash(body)), []byte(signed)) != 1 {
+		return errors.New("body does not match signed content hash")
+	}
+	return nil
+}
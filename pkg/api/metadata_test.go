@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+)
+
+func TestCtxWithAuth(t *testing.T) {
+	tests := []struct {
+		name        string
+		authorizer  map[string]interface{}
+		wantErr     bool
+		role        auth.Role
+		permissions int
+		identifier  string
+		isUser      bool
+		isDevice    bool
+	}{
+		{name: "jwt guest", authorizer: auth.AuthorizerContext("1", auth.Guest, auth.JWT), wantErr: true},
+		{name: "jwt device", authorizer: auth.AuthorizerContext("1", auth.Device, auth.JWT), wantErr: true},
+		{name: "jwt user", authorizer: auth.AuthorizerContext("1", auth.User, auth.JWT), role: auth.User, permissions: 5, identifier: "1", isUser: true},
+		{name: "jwt superuser", authorizer: auth.AuthorizerContext("1", auth.SuperUser, auth.JWT), role: auth.SuperUser, permissions: 7, identifier: "1", isUser: true},
+		{name: "jwt manager", authorizer: auth.AuthorizerContext("1", auth.Manager, auth.JWT), role: auth.Manager, permissions: 10, identifier: "1", isUser: true},
+		{name: "jwt admin", authorizer: auth.AuthorizerContext("1", auth.Admin, auth.JWT), role: auth.Admin, permissions: 15, identifier: "1", isUser: true},
+		{name: "jwt superadmin", authorizer: auth.AuthorizerContext("1", auth.SuperAdmin, auth.JWT), role: auth.SuperAdmin, permissions: 20, identifier: "1", isUser: true},
+		{name: "hmac guest", authorizer: auth.AuthorizerContext("", auth.Guest, auth.HMAC), wantErr: true},
+		{name: "hmac device", authorizer: auth.AuthorizerContext("key", auth.Device, auth.HMAC), role: auth.Device, permissions: 3, identifier: "key", isDevice: true},
+		{name: "hmac shared device", authorizer: auth.AuthorizerContext("", auth.Device, auth.HMAC), role: auth.Device, permissions: 3, identifier: unknownIdentifier, isDevice: true},
+		{name: "hmac user", authorizer: auth.AuthorizerContext("1", auth.User, auth.HMAC), wantErr: true},
+		{name: "hmac admin", authorizer: auth.AuthorizerContext("1", auth.Admin, auth.HMAC), wantErr: true},
+		{name: "unknown role", authorizer: map[string]interface{}{"role": "8", "kind": "2"}, wantErr: true},
+		{name: "unknown kind", authorizer: map[string]interface{}{"role": "3", "kind": "3"}, wantErr: true},
+		{name: "missing role", authorizer: map[string]interface{}{"kind": "2"}, wantErr: true},
+		{name: "missing kind", authorizer: map[string]interface{}{"role": "3"}, wantErr: true},
+		{name: "invalid permissions", authorizer: map[string]interface{}{"role": "3", "kind": "2", "permissions": "x"}, wantErr: true},
+		{name: "permissions default to role", authorizer: map[string]interface{}{"role": "5", "kind": "2", "identifier": "1"}, role: auth.Manager, permissions: 10, identifier: "1", isUser: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := ctxWithAuth(context.Background(), tt.authorizer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ctxWithAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, ok := GetMetaData(ctx); ok {
+					t.Error("metadata set for rejected context")
+				}
+				return
+			}
+			meta := MustGetMetaData(ctx)
+			if meta.GetRole() != tt.role {
+				t.Errorf("role = %v, want %v", meta.GetRole(), tt.role)
+			}
+			if meta.GetPermissions() != tt.permissions {
+				t.Errorf("permissions = %d, want %d", meta.GetPermissions(), tt.permissions)
+			}
+			if meta.GetIdentifier() != tt.identifier {
+				t.Errorf("identifier = %q, want %q", meta.GetIdentifier(), tt.identifier)
+			}
+			if meta.HasIdentifier() != (tt.identifier != unknownIdentifier) {
+				t.Errorf("HasIdentifier() = %v for %q", meta.HasIdentifier(), tt.identifier)
+			}
+			if meta.IsUser() != tt.isUser || meta.IsDevice() != tt.isDevice {
+				t.Errorf("IsUser() = %v, IsDevice() = %v, want %v, %v", meta.IsUser(), meta.IsDevice(), tt.isUser, tt.isDevice)
+			}
+		})
+	}
+}
+
+func TestCanView(t *testing.T) {
+	callers := map[string]map[string]interface{}{
+		"device":     auth.AuthorizerContext("key", auth.Device, auth.HMAC),
+		"shared":     auth.AuthorizerContext("", auth.Device, auth.HMAC),
+		"owner":      auth.AuthorizerContext("owner", auth.User, auth.JWT),
+		"other user": auth.AuthorizerContext("other", auth.SuperUser, auth.JWT),
+		"manager":    auth.AuthorizerContext("manager", auth.Manager, auth.JWT),
+	}
+	// device key equal to the owner id must not pass for the owner.
+	callers["device as owner"] = auth.AuthorizerContext("owner", auth.Device, auth.HMAC)
+
+	tests := []struct {
+		caller     string
+		wantPublic bool // CanView of a public item
+		wantHidden bool // CanView of a non public item, equals CanManage
+	}{
+		{caller: "device", wantPublic: true},
+		{caller: "shared", wantPublic: true},
+		{caller: "device as owner", wantPublic: true},
+		{caller: "other user", wantPublic: true},
+		{caller: "owner", wantPublic: true, wantHidden: true},
+		{caller: "manager", wantPublic: true, wantHidden: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.caller, func(t *testing.T) {
+			ctx, err := ctxWithAuth(context.Background(), callers[tt.caller])
+			if err != nil {
+				t.Fatalf("ctxWithAuth() error = %v", err)
+			}
+			meta := MustGetMetaData(ctx)
+			if got := meta.CanView("owner", true); got != tt.wantPublic {
+				t.Errorf("CanView(public) = %v, want %v", got, tt.wantPublic)
+			}
+			if got := meta.CanView("owner", false); got != tt.wantHidden {
+				t.Errorf("CanView(hidden) = %v, want %v", got, tt.wantHidden)
+			}
+			if got := meta.CanManage("owner"); got != tt.wantHidden {
+				t.Errorf("CanManage() = %v, want %v", got, tt.wantHidden)
+			}
+			if meta.CanView("", false) != meta.HasPermissions(auth.Manager) {
+				t.Errorf("CanView() of an item without owner = %v", meta.CanView("", false))
+			}
+		})
+	}
+}
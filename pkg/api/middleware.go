@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Middleware wraps HandleFunc with additional behaviour.
+type Middleware func(HandleFunc) HandleFunc
+
+// TypedHandleFunc is a HandleFunc which receives an already decoded and validated request body.
+type TypedHandleFunc[T any] func(context.Context, zerolog.Logger, T, openapi.QueryParams, PathParams) (any, *HandleError)
+
+// Chain applies middlewares to handler, the first middleware is the outermost one.
+func Chain(handler HandleFunc, middlewares ...Middleware) HandleFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// RequireRole rejects requests with a role lower than the provided one.
+func RequireRole(role auth.Role) Middleware {
+	return requireAuth(role, false)
+}
+
+// RequireUser accepts user tokens with the provided role or higher, devices and guests are rejected.
+func RequireUser(role auth.Role) Middleware {
+	return requireAuth(role, true)
+}
+
+// RequireDevice accepts device requests only.
+func RequireDevice() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, query openapi.QueryParams, path PathParams) (any, *HandleError) {
+			meta, ok := GetMetaData(ctx)
+			if !ok {
+				return nil, &HandleError{Status: http.StatusUnauthorized, Err: errors.New("metadata not found in context")}
+			}
+			if !meta.IsDevice() {
+				return nil, &HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions"), Message: "insufficient permissions"}
+			}
+			return next(ctx, logger, body, query, path)
+		}
+	}
+}
+
+func requireAuth(role auth.Role, userOnly bool) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, query openapi.QueryParams, path PathParams) (any, *HandleError) {
+			meta, ok := GetMetaData(ctx)
+			if !ok {
+				return nil, &HandleError{Status: http.StatusUnauthorized, Err: errors.New("metadata not found in context")}
+			}
+			if (userOnly && !meta.IsUser()) || !meta.HasPermissions(role) {
+				return nil, &HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions"), Message: "insufficient permissions"}
+			}
+			return next(ctx, logger, body, query, path)
+		}
+	}
+}
+
+// Timing logs handler execution time.
+func Timing() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, query openapi.QueryParams, path PathParams) (any, *HandleError) {
+			start := time.Now()
+			result, handleErr := next(ctx, logger, body, query, path)
+
+			event := logger.Info().Dur("duration", time.Since(start))
+			if handleErr != nil {
+				event.Int("status", handleErr.Status)
+			}
+			event.Msg("Handler finished")
+			return result, handleErr
+		}
+	}
+}
+
+// WithBody decodes request body into T, validates it and passes the result to handler.
+func WithBody[T any](v *validator.Validator, handler TypedHandleFunc[T]) HandleFunc {
+	if v == nil || handler == nil {
+		panic("validator and handler cannot be nil")
+	}
+	return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, query openapi.QueryParams, path PathParams) (any, *HandleError) {
+		var req T
+		if err := serializer.UnmarshalJSON(body, &req); err != nil {
+			return nil, &HandleError{Status: http.StatusBadRequest, Err: err, Message: "malformed request body"}
+		}
+		if err := v.ValidateStruct(&req); err != nil {
+			return nil, &HandleError{Status: http.StatusBadRequest, Err: err}
+		}
+		return handler(ctx, logger, req, query, path)
+	}
+}
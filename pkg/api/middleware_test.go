@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRequireMiddlewares(t *testing.T) {
+	callers := map[string]map[string]interface{}{
+		"device":     auth.AuthorizerContext("key", auth.Device, auth.HMAC),
+		"user":       auth.AuthorizerContext("1", auth.User, auth.JWT),
+		"superuser":  auth.AuthorizerContext("1", auth.SuperUser, auth.JWT),
+		"manager":    auth.AuthorizerContext("1", auth.Manager, auth.JWT),
+		"admin":      auth.AuthorizerContext("1", auth.Admin, auth.JWT),
+		"superadmin": auth.AuthorizerContext("1", auth.SuperAdmin, auth.JWT),
+	}
+	middlewares := map[string]Middleware{
+		"RequireRole(Device)":  RequireRole(auth.Device),
+		"RequireRole(Manager)": RequireRole(auth.Manager),
+		"RequireUser(User)":    RequireUser(auth.User),
+		"RequireUser(Manager)": RequireUser(auth.Manager),
+		"RequireDevice":        RequireDevice(),
+	}
+
+	// status of every middleware per caller, 0 when the request passes.
+	tests := []struct {
+		caller string
+		want   map[string]int
+	}{
+		{caller: "guest", want: map[string]int{
+			"RequireRole(Device)":  http.StatusForbidden,
+			"RequireRole(Manager)": http.StatusForbidden,
+			"RequireUser(User)":    http.StatusForbidden,
+			"RequireUser(Manager)": http.StatusForbidden,
+			"RequireDevice":        http.StatusForbidden,
+		}},
+		{caller: "device", want: map[string]int{
+			"RequireRole(Device)":  0,
+			"RequireRole(Manager)": http.StatusForbidden,
+			"RequireUser(User)":    http.StatusForbidden,
+			"RequireUser(Manager)": http.StatusForbidden,
+			"RequireDevice":        0,
+		}},
+		{caller: "user", want: map[string]int{
+			"RequireRole(Device)":  0,
+			"RequireRole(Manager)": http.StatusForbidden,
+			"RequireUser(User)":    0,
+			"RequireUser(Manager)": http.StatusForbidden,
+			"RequireDevice":        http.StatusForbidden,
+		}},
+		{caller: "superuser", want: map[string]int{
+			"RequireRole(Device)":  0,
+			"RequireRole(Manager)": http.StatusForbidden,
+			"RequireUser(User)":    0,
+			"RequireUser(Manager)": http.StatusForbidden,
+			"RequireDevice":        http.StatusForbidden,
+		}},
+		{caller: "manager", want: map[string]int{
+			"RequireRole(Device)":  0,
+			"RequireRole(Manager)": 0,
+			"RequireUser(User)":    0,
+			"RequireUser(Manager)": 0,
+			"RequireDevice":        http.StatusForbidden,
+		}},
+		{caller: "admin", want: map[string]int{
+			"RequireRole(Device)":  0,
+			"RequireRole(Manager)": 0,
+			"RequireUser(User)":    0,
+			"RequireUser(Manager)": 0,
+			"RequireDevice":        http.StatusForbidden,
+		}},
+		{caller: "superadmin", want: map[string]int{
+			"RequireRole(Device)":  0,
+			"RequireRole(Manager)": 0,
+			"RequireUser(User)":    0,
+			"RequireUser(Manager)": 0,
+			"RequireDevice":        http.StatusForbidden,
+		}},
+		{caller: "no metadata", want: map[string]int{
+			"RequireRole(Device)":  http.StatusUnauthorized,
+			"RequireRole(Manager)": http.StatusUnauthorized,
+			"RequireUser(User)":    http.StatusUnauthorized,
+			"RequireUser(Manager)": http.StatusUnauthorized,
+			"RequireDevice":        http.StatusUnauthorized,
+		}},
+	}
+
+	handler := func(context.Context, zerolog.Logger, json.RawMessage, openapi.QueryParams, PathParams) (any, *HandleError) {
+		return "ok", nil
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		switch authorizer, ok := callers[tt.caller]; {
+		case ok:
+			var err error
+			if ctx, err = ctxWithAuth(ctx, authorizer); err != nil {
+				t.Fatalf("%s: ctxWithAuth() error = %v", tt.caller, err)
+			}
+		case tt.caller == "guest":
+			ctx = ctxWithGuest(ctx)
+		}
+
+		for name, want := range tt.want {
+			t.Run(tt.caller+"/"+name, func(t *testing.T) {
+				_, herr := Chain(handler, middlewares[name])(ctx, zerolog.Nop(), nil, openapi.QueryParams{}, nil)
+				got := 0
+				if herr != nil {
+					got = herr.Status
+				}
+				if got != want {
+					t.Errorf("status = %d, want %d", got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+// payloadVersionV2 is the "version" of HTTP API (payload v2) and Lambda Function URL events.
+const payloadVersionV2 = "2.0"
+
+// RequestAuthorizer builds authorizer context for events which do not carry one, e.g. Lambda Function URLs.
+// Returned map has the same keys as the context set by the API Gateway authorizer.
+type RequestAuthorizer func(ctx context.Context, headers map[string]string) (map[string]interface{}, error)
+
+// request is a normalized HTTP request built from any supported Lambda event.
+type request struct {
+	method     string
+	path       string
+	headers    map[string]string
+	query      map[string]string
+	multiQuery map[string][]string
+	body       string
+	isBase64   bool
+
+	requestID  string
+	domainName string
+	sourceIP   string
+	userAgent  string
+	authorizer map[string]interface{}
+}
+
+func requestFromV1(e events.APIGatewayProxyRequest) *request {
+	return &request{
+		method:     e.HTTPMethod,
+		path:       e.Path,
+		headers:    e.Headers,
+		query:      e.QueryStringParameters,
+		multiQuery: e.MultiValueQueryStringParameters,
+		body:       e.Body,
+		isBase64:   e.IsBase64Encoded,
+		requestID:  e.RequestContext.RequestID,
+		domainName: e.RequestContext.DomainName,
+		sourceIP:   e.RequestContext.Identity.SourceIP,
+		userAgent:  e.RequestContext.Identity.UserAgent,
+		authorizer: e.RequestContext.Authorizer,
+	}
+}
+
+func requestFromV2(e events.APIGatewayV2HTTPRequest) *request {
+	req := &request{
+		method:     e.RequestContext.HTTP.Method,
+		path:       stripStage(e.RawPath, e.RequestContext.Stage),
+		headers:    e.Headers,
+		body:       e.Body,
+		isBase64:   e.IsBase64Encoded,
+		requestID:  e.RequestContext.RequestID,
+		domainName: e.RequestContext.DomainName,
+		sourceIP:   e.RequestContext.HTTP.SourceIP,
+		userAgent:  e.RequestContext.HTTP.UserAgent,
+	}
+	req.query, req.multiQuery = parseRawQuery(e.RawQueryString, e.QueryStringParameters)
+	if e.RequestContext.Authorizer != nil {
+		req.authorizer = e.RequestContext.Authorizer.Lambda
+	}
+	return req
+}
+
+func requestFromFunctionURL(e events.LambdaFunctionURLRequest) *request {
+	req := &request{
+		method:     e.RequestContext.HTTP.Method,
+		path:       e.RawPath,
+		headers:    e.Headers,
+		body:       e.Body,
+		isBase64:   e.IsBase64Encoded,
+		requestID:  e.RequestContext.RequestID,
+		domainName: e.RequestContext.DomainName,
+		sourceIP:   e.RequestContext.HTTP.SourceIP,
+		userAgent:  e.RequestContext.HTTP.UserAgent,
+	}
+	req.query, req.multiQuery = parseRawQuery(e.RawQueryString, e.QueryStringParameters)
+	return req
+}
+
+// parseRawQuery restores repeated query parameters, payload v2 joins them with commas in the map.
+func parseRawQuery(raw string, params map[string]string) (map[string]string, map[string][]string) {
+	values, err := url.ParseQuery(raw)
+	if err != nil || len(values) == 0 {
+		return params, nil
+	}
+
+	query := make(map[string]string, len(values))
+	for k, v := range values {
+		query[k] = v[len(v)-1]
+	}
+	return query, values
+}
+
+// stripStage removes named stage prefix which HTTP API keeps in the raw path.
+func stripStage(path, stage string) string {
+	if stage == "" || stage == "$default" {
+		return path
+	}
+	if trimmed := strings.TrimPrefix(path, "/"+stage); trimmed != path && (trimmed == "" || trimmed[0] == '/') {
+		return "/" + strings.TrimPrefix(trimmed, "/")
+	}
+	return path
+}
+
+// isFunctionURL reports whether payload v2 event came from Lambda Function URL.
+func isFunctionURL(domainName string) bool {
+	return strings.Contains(domainName, ".lambda-url.")
+}
+
+// eventHeader is used to detect event type before decoding the whole payload.
+type eventHeader struct {
+	Version        string `json:"version"`
+	RequestContext struct {
+		DomainName string `json:"domainName"`
+	} `json:"requestContext"`
+}
+
+// HandleEvent detects the event type and dispatches REST API, HTTP API and Function URL events.
+func (a *API) HandleEvent(ctx context.Context, payload json.RawMessage) (any, error) {
+	var header eventHeader
+	if err := json.Unmarshal(payload, &header); err != nil {
+		return nil, errors.Wrap(err, "failed to decode event")
+	}
+
+	switch {
+	case header.Version == payloadVersionV2 && isFunctionURL(header.RequestContext.DomainName):
+		var e events.LambdaFunctionURLRequest
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, errors.Wrap(err, "failed to decode Function URL event")
+		}
+		return a.HandleFunctionURL(ctx, e)
+	case header.Version == payloadVersionV2:
+		var e events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, errors.Wrap(err, "failed to decode HTTP API event")
+		}
+		return a.HandleHTTP(ctx, e)
+	default:
+		var e events.APIGatewayProxyRequest
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, errors.Wrap(err, "failed to decode REST API event")
+		}
+		return a.Handle(ctx, e)
+	}
+}
+
+// HandleHTTP processes API Gateway HTTP API (payload v2) event.
+func (a *API) HandleHTTP(ctx context.Context, e events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	resp, err := a.serve(ctx, requestFromV2(e))
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:        resp.StatusCode,
+		Headers:           resp.Headers,
+		MultiValueHeaders: resp.MultiValueHeaders,
+		Body:              resp.Body,
+		IsBase64Encoded:   resp.IsBase64Encoded,
+	}, err
+}
+
+// HandleFunctionURL processes Lambda Function URL event, Config.Authorizer is used for authorization.
+func (a *API) HandleFunctionURL(ctx context.Context, e events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+	resp, err := a.serve(ctx, requestFromFunctionURL(e))
+	return events.LambdaFunctionURLResponse{
+		StatusCode:      resp.StatusCode,
+		Headers:         resp.Headers,
+		Body:            resp.Body,
+		IsBase64Encoded: resp.IsBase64Encoded,
+	}, err
+}
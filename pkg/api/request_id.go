@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/rs/zerolog"
+)
+
+// HeaderRequestID is a response header with the request correlation ID.
+const HeaderRequestID = "X-Request-Id"
+
+const requestIDKey contextKey = "request_id"
+
+// GetRequestID returns the request correlation ID stored in context.
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// requestIDs returns the API Gateway request ID used as correlation ID and the Lambda invocation ID.
+// Correlation ID is generated if API Gateway did not provide one.
+func requestIDs(ctx context.Context, req *request) (requestID, lambdaID string) {
+	if requestID = req.requestID; requestID == "" {
+		requestID = newRequestID()
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		lambdaID = lc.AwsRequestID
+	}
+	return requestID, lambdaID
+}
+
+// requestLogger returns logger with request IDs attached to every entry.
+func requestLogger(log zerolog.Logger, requestID, lambdaID string) zerolog.Logger {
+	c := log.With().Str("request_id", requestID)
+	if lambdaID != "" {
+		c = c.Str("lambda_request_id", lambdaID)
+	}
+	return c.Logger()
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
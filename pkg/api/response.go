@@ -2,11 +2,44 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
 	"github.com/aws/aws-lambda-go/events"
 )
 
+// Response can be returned by handlers to set status and headers along with the body.
+type Response struct {
+	// Status overrides the default method status, e.g. 201 for POST, when not zero.
+	Status  int
+	Headers map[string]string
+	Body    any
+}
+
+// NewResponse creates Response with the body and default status.
+func NewResponse(body any) *Response {
+	return &Response{Body: body, Headers: make(map[string]string)}
+}
+
+// WithHeader sets response header.
+func (r *Response) WithHeader(key, value string) *Response {
+	if r.Headers == nil {
+		r.Headers = make(map[string]string)
+	}
+	r.Headers[key] = value
+	return r
+}
+
+// WithCacheControl sets Cache-Control header, e.g. "private, max-age=300".
+func (r *Response) WithCacheControl(value string) *Response {
+	return r.WithHeader(headerCacheControl, value)
+}
+
+// WithLastModified sets Last-Modified header used for If-Modified-Since checks.
+func (r *Response) WithLastModified(t time.Time) *Response {
+	return r.WithHeader(headerLastModified, t.UTC().Format(http.TimeFormat))
+}
+
 func gatewayResponse(statusCode int, body any, headers map[string]string) (events.APIGatewayProxyResponse, error) {
 	if headers == nil {
 		headers = make(map[string]string)
@@ -16,6 +49,13 @@ func gatewayResponse(statusCode int, body any, headers map[string]string) (event
 		headers["Content-Type"] = "application/json"
 	}
 
+	if body == nil || statusCode == http.StatusNoContent {
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+		}, nil
+	}
+
 	jsonBody, err := serializer.MarshalJSON(body)
 	if err != nil {
 		return events.APIGatewayProxyResponse{
@@ -25,9 +65,16 @@ func gatewayResponse(statusCode int, body any, headers map[string]string) (event
 		}, err
 	}
 
+	if _, exists := headers[headerETag]; !exists && statusCode == http.StatusOK {
+		headers[headerETag] = strongETag(jsonBody)
+	}
 	return events.APIGatewayProxyResponse{
 		StatusCode: statusCode,
 		Headers:    headers,
 		Body:       string(jsonBody),
 	}, nil
 }
+
+func errorResponse(e *HandleError, requestID string, headers map[string]string) (events.APIGatewayProxyResponse, error) {
+	return gatewayResponse(e.Status, e.response(requestID), headers)
+}
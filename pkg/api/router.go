@@ -0,0 +1,193 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// PathParams holds values extracted from templated path segments, e.g. "{id}".
+type PathParams map[string]string
+
+// Get returns path parameter value or empty string if it does not exist.
+func (p PathParams) Get(key string) string {
+	return p[key]
+}
+
+// GetString returns path parameter value or error if it does not exist.
+func (p PathParams) GetString(key string) (string, error) {
+	v, ok := p[key]
+	if !ok || v == "" {
+		return "", fmt.Errorf("path parameter '%s' not found", key)
+	}
+	return v, nil
+}
+
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  HandleFunc
+	public   bool
+}
+
+// router matches "METHOD /path/{param}" keys against incoming requests.
+type router struct {
+	routes []route
+}
+
+// match is a result of router lookup.
+type match struct {
+	route  *route
+	params PathParams
+
+	// allowed lists methods registered for the path, filled when the path is known.
+	allowed []string
+	// implicit is true for HEAD and OPTIONS requests which have no explicit route.
+	implicit bool
+}
+
+func newRouter(handlers map[string]HandleFunc, middlewares ...Middleware) *router {
+	r := &router{routes: make([]route, 0, len(handlers))}
+	for key, handler := range handlers {
+		if handler == nil {
+			panic(fmt.Sprintf("handler for '%s' cannot be nil", key))
+		}
+		method, pattern, ok := strings.Cut(strings.TrimSpace(key), " ")
+		if !ok || !strings.HasPrefix(pattern, "/") {
+			panic(fmt.Sprintf("invalid route '%s', expected 'METHOD /path'", key))
+		}
+		segments := splitPath(pattern)
+		for _, s := range segments {
+			if strings.ContainsAny(s, "{}") && !isParamSegment(s) {
+				panic(fmt.Sprintf("invalid path segment '%s' in route '%s'", s, key))
+			}
+		}
+		r.routes = append(r.routes, route{
+			method:   strings.ToUpper(method),
+			pattern:  pattern,
+			segments: segments,
+			handler:  Chain(handler, middlewares...),
+		})
+	}
+
+	// Static segments win over parameters, so "/v1/dictionaries/search" is
+	// matched before "/v1/dictionaries/{id}" regardless of map iteration order.
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return routeWeight(r.routes[i].segments) > routeWeight(r.routes[j].segments)
+	})
+	return r
+}
+
+// markPublic marks routes which are served without authorizer context.
+func (r *router) markPublic(keys []string) {
+	for _, key := range keys {
+		method, pattern, _ := strings.Cut(strings.TrimSpace(key), " ")
+		found := false
+		for i := range r.routes {
+			if r.routes[i].method == strings.ToUpper(method) && r.routes[i].pattern == pattern {
+				r.routes[i].public, found = true, true
+			}
+		}
+		if !found {
+			panic(fmt.Sprintf("public route '%s' has no handler", key))
+		}
+	}
+}
+
+// lookup returns matched route for method and path, ok is false when path is unknown.
+func (r *router) lookup(method, path string) (m match, ok bool) {
+	var (
+		segments = splitPath(path)
+		methods  = make(map[string]struct{})
+		fallback *route
+		params   PathParams
+	)
+	method = strings.ToUpper(method)
+
+	for i := range r.routes {
+		rt := &r.routes[i]
+		p, matched := rt.match(segments)
+		if !matched {
+			continue
+		}
+		methods[rt.method] = struct{}{}
+
+		if m.route == nil && rt.method == method {
+			m.route, m.params = rt, p
+		}
+		if fallback == nil && method == http.MethodHead && rt.method == http.MethodGet {
+			fallback, params = rt, p
+		}
+	}
+	if len(methods) == 0 {
+		return m, false
+	}
+
+	if _, ok := methods[http.MethodGet]; ok {
+		methods[http.MethodHead] = struct{}{}
+	}
+	methods[http.MethodOptions] = struct{}{}
+	for k := range methods {
+		m.allowed = append(m.allowed, k)
+	}
+	sort.Strings(m.allowed)
+
+	if m.route == nil {
+		switch {
+		case fallback != nil:
+			m.route, m.params, m.implicit = fallback, params, true
+		case method == http.MethodOptions:
+			m.implicit = true
+		}
+	}
+	return m, true
+}
+
+func (rt *route) match(segments []string) (PathParams, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	var params PathParams
+	for i, s := range rt.segments {
+		if isParamSegment(s) {
+			if segments[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(PathParams)
+			}
+			params[s[1:len(s)-1]] = segments[i]
+			continue
+		}
+		if s != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func routeWeight(segments []string) int {
+	weight := 0
+	for _, s := range segments {
+		weight <<= 1
+		if !isParamSegment(s) {
+			weight |= 1
+		}
+	}
+	return weight
+}
+
+func isParamSegment(s string) bool {
+	return len(s) > 2 && strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}")
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
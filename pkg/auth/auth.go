@@ -1,12 +1,16 @@
 package auth
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 const (
-	TimestampDelay  = 15
-	HeaderTimestamp = "x-timestamp"
-	HeaderSignature = "x-signature"
-	HeaderAuth      = "Authorization"
+	TimestampDelay      = 15
+	HeaderTimestamp     = "x-timestamp"
+	HeaderSignature     = "x-signature"
+	HeaderAuth          = "Authorization"
+	HeaderContentSHA256 = "x-content-sha256"
 )
 
 // Authenticator provides the main authentication functionality
@@ -33,12 +37,33 @@ func (a *Authenticator) ValidateDeviceRequest(timestamp, signature string) error
 	return a.hmac.ValidateRequest(timestamp, signature)
 }
 
+// ValidateDeviceKeyRequest validates request of a registered device signed with its secret.
+func (a *Authenticator) ValidateDeviceKeyRequest(secret, timestamp, signature string) error {
+	return NewHMACAuth(secret).ValidateRequest(timestamp, signature)
+}
+
+// ValidateSignedDeviceRequest validates request-bound signature made with the shared device token.
+func (a *Authenticator) ValidateSignedDeviceRequest(req SignedRequest, signature string) error {
+	return a.hmac.ValidateSignedRequest(req, signature)
+}
+
+// ValidateSignedDeviceKeyRequest validates request-bound signature of a registered device.
+func (a *Authenticator) ValidateSignedDeviceKeyRequest(secret string, req SignedRequest, signature string) error {
+	return NewHMACAuth(secret).ValidateSignedRequest(req, signature)
+}
+
+// WithKeySet sets verification keys of asymmetric JWT tokens.
+func (a *Authenticator) WithKeySet(keys *KeySet) *Authenticator {
+	a.jwt.WithKeySet(keys)
+	return a
+}
+
 // ValidateJWTToken validates JWT token and returns claims
-func (a *Authenticator) ValidateJWTToken(tokenString string) (*Claims, error) {
-	return a.jwt.ValidateToken(tokenString)
+func (a *Authenticator) ValidateJWTToken(ctx context.Context, tokenString string) (*Claims, error) {
+	return a.jwt.ValidateToken(ctx, tokenString)
 }
 
 // GenerateToken generates new JWT token
-func (a *Authenticator) GenerateToken(userID int, role Role, expiresIn time.Duration) (string, error) {
-	return a.jwt.GenerateToken(userID, role, expiresIn)
+func (a *Authenticator) GenerateToken(userID int, role Role, tokenID string, expiresIn time.Duration) (string, error) {
+	return a.jwt.GenerateToken(userID, role, tokenID, expiresIn)
 }
@@ -0,0 +1,17 @@
+package auth
+
+import "strconv"
+
+// AuthorizerContext returns the context the authorizer passes to services: the role with its permission level
+// and the kind of credentials. Empty identifier is omitted, services see such callers as unidentified.
+func AuthorizerContext(identifier string, role Role, kind Kind) map[string]interface{} {
+	context := map[string]interface{}{
+		"permissions": strconv.Itoa(GetPermissionLevel(role)),
+		"role":        strconv.Itoa(int(role)),
+		"kind":        strconv.Itoa(int(kind)),
+	}
+	if identifier != "" {
+		context["identifier"] = identifier
+	}
+	return context
+}
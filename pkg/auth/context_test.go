@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const testSecret = "test-secret"
+
+// signClaims signs claims with the test secret, it bypasses GenerateToken checks to forge any role.
+func signClaims(t *testing.T, role Role) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Identifier: 42,
+		Role:       role,
+		StandardClaims: jwt.StandardClaims{
+			Id:        "session",
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		},
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestUserTokenClaimsPassedByAuthorizer(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        Role
+		permissions string
+		wantErr     error
+	}{
+		{name: "unknown role", role: 0, wantErr: ErrInvalidTokenRole},
+		{name: "guest", role: Guest, wantErr: ErrInvalidTokenRole},
+		{name: "device", role: Device, wantErr: ErrInvalidTokenRole},
+		{name: "user", role: User, permissions: "5"},
+		{name: "superuser", role: SuperUser, permissions: "7"},
+		{name: "manager", role: Manager, permissions: "10"},
+		{name: "admin", role: Admin, permissions: "15"},
+		{name: "superadmin", role: SuperAdmin, permissions: "20"},
+		{name: "role above superadmin", role: SuperAdmin + 1, wantErr: ErrInvalidTokenRole},
+	}
+	j := NewJWTAuth(testSecret)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := j.GenerateToken(42, tt.role, "session", time.Minute); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateToken() error = %v, want %v", err, tt.wantErr)
+			}
+
+			claims, err := j.ValidateToken(context.Background(), signClaims(t, tt.role))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if claims.Id != "session" {
+				t.Errorf("session id = %q, want %q", claims.Id, "session")
+			}
+
+			got := AuthorizerContext(strconv.Itoa(claims.Identifier), claims.Role, JWT)
+			want := map[string]string{
+				"identifier":  "42",
+				"role":        strconv.Itoa(int(tt.role)),
+				"permissions": tt.permissions,
+				"kind":        strconv.Itoa(int(JWT)),
+			}
+			if len(got) != len(want) {
+				t.Fatalf("context = %v, want %v", got, want)
+			}
+			for k, v := range want {
+				if got[k] != v {
+					t.Errorf("context[%q] = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestDeviceAuthorizerContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		wantID     bool
+	}{
+		{name: "device key", identifier: "0123456789abcdef0123456789abcdef", wantID: true},
+		{name: "shared token", identifier: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AuthorizerContext(tt.identifier, Device, HMAC)
+			if got["role"] != "2" || got["permissions"] != "3" || got["kind"] != "1" {
+				t.Errorf("context = %v, want device role, permissions 3 and hmac kind", got)
+			}
+			if _, ok := got["identifier"]; ok != tt.wantID {
+				t.Errorf("identifier present = %v, want %v", ok, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TokenSeparator separates parts of the x-api-auth header.
+	TokenSeparator = ":::"
+	// SharedKeyID is the key ID of request-bound signatures made with the shared device token.
+	SharedKeyID = "shared"
+
+	deviceKeyIDBytes  = 16
+	deviceSecretBytes = 32
+)
+
+// DeviceToken is a parsed device x-api-auth header, supported formats:
+//
+//	keyID:::timestamp:::nonce:::signature - request-bound signature, see SignedRequest;
+//	keyID:::timestamp:::signature         - legacy signature of the timestamp;
+//	timestamp:::signature                 - legacy signature with the shared device token.
+type DeviceToken struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+}
+
+// Shared reports whether the token is signed with the shared device token.
+func (t DeviceToken) Shared() bool {
+	return t.KeyID == "" || t.KeyID == SharedKeyID
+}
+
+// RequestBound reports whether the signature covers the whole request.
+func (t DeviceToken) RequestBound() bool {
+	return t.Nonce != ""
+}
+
+// ParseDeviceToken parses device x-api-auth header, ok is false for other formats.
+func ParseDeviceToken(header string) (DeviceToken, bool) {
+	parts := strings.Split(header, TokenSeparator)
+	switch len(parts) {
+	case 2:
+		return DeviceToken{Timestamp: parts[0], Signature: parts[1]}, true
+	case 3:
+		return DeviceToken{KeyID: parts[0], Timestamp: parts[1], Signature: parts[2]}, true
+	case 4:
+		if parts[2] == "" {
+			return DeviceToken{}, false
+		}
+		return DeviceToken{KeyID: parts[0], Timestamp: parts[1], Nonce: parts[2], Signature: parts[3]}, true
+	default:
+		return DeviceToken{}, false
+	}
+}
+
+// NewDeviceCredentials generates key ID and secret for a new device,
+// the secret is returned to the device once and stored encrypted, see DeviceSecretContext.
+func NewDeviceCredentials() (keyID, secret string, err error) {
+	if keyID, err = randomHex(deviceKeyIDBytes); err != nil {
+		return "", "", err
+	}
+	if secret, err = randomHex(deviceSecretBytes); err != nil {
+		return "", "", err
+	}
+	return keyID, secret, nil
+}
+
+// DeviceSecretContext returns KMS encryption context of the device secret. It binds the ciphertext
+// to the key ID, so a ciphertext copied to another device item does not decrypt.
+func DeviceSecretContext(keyID string) map[string]string {
+	return map[string]string{"key_id": keyID}
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate random bytes")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -9,8 +9,26 @@ var (
 	ErrTimestampParse   = errors.New("cannot parse timestamp")
 	ErrTimestampExpired = errors.New("timestamp expired or not yet valid")
 	ErrInvalidSignature = errors.New("invalid signature")
+	ErrDeviceKeyUnknown = errors.New("device key is unknown")
+	ErrDeviceKeyRevoked = errors.New("device key is revoked")
+	ErrInvalidNonce     = errors.New("invalid nonce")
+	ErrNonceReused      = errors.New("nonce was already used")
+	ErrLegacySignature  = errors.New("legacy signature is not accepted")
+	ErrSharedTokenRoute = errors.New("shared device token is accepted for registration only")
 
 	// JWT authentication errors
 	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
 	ErrInvalidTokenClaims      = errors.New("invalid token claims")
+	ErrInvalidTokenRole        = errors.New("invalid token role")
+	ErrMissingKeyID            = errors.New("key id is missing")
+	ErrUnknownKeyID            = errors.New("key id is unknown")
+	ErrUnsupportedKey          = errors.New("unsupported key")
+	ErrNoSigningKey            = errors.New("signing key is not configured")
+	ErrMissingTokenID          = errors.New("token id is missing")
+	ErrTokenRevoked            = errors.New("token is revoked")
+
+	// Login and refresh token errors
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidRefresh     = errors.New("invalid refresh token")
+	ErrRefreshReused      = errors.New("refresh token was already used")
 )
@@ -3,18 +3,77 @@ package auth
 import (
 	"crypto/hmac"
 	"encoding/hex"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	sha256 "github.com/minio/sha256-simd"
 	"github.com/pkg/errors"
 )
 
+const (
+	nonceMinLen = 16
+	nonceMaxLen = 64
+)
+
 // HMACAuth handles HMAC-based authentication
 type HMACAuth struct {
 	secret []byte
 }
 
+// SignedRequest is a device request in the form covered by the request-bound signature.
+type SignedRequest struct {
+	Method        string
+	Path          string
+	Query         map[string][]string
+	ContentSHA256 string
+	Timestamp     string
+	Nonce         string
+}
+
+// Canonical returns the signed string, fields are separated by new lines:
+//
+//	METHOD
+//	/path
+//	a=1&b=2&b=3
+//	hex SHA-256 of the body
+//	timestamp
+//	nonce
+//
+// Query parameters are sorted by name and value, both are URL-escaped.
+func (r SignedRequest) Canonical() string {
+	names := make([]string, 0, len(r.Query))
+	for name := range r.Query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		values := append([]string(nil), r.Query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, url.QueryEscape(name)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join([]string{
+		strings.ToUpper(r.Method),
+		r.Path,
+		strings.Join(pairs, "&"),
+		r.ContentSHA256,
+		r.Timestamp,
+		r.Nonce,
+	}, "\n")
+}
+
+// PayloadHash returns hex encoded SHA-256 of the request body.
+func PayloadHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
 // NewHMACAuth creates new HMAC authenticator instance
 func NewHMACAuth(secret string) *HMACAuth {
 	return &HMACAuth{
@@ -29,11 +88,43 @@ func (h *HMACAuth) GenerateSignature(data string) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
-// ValidateRequest validates HMAC-based request
+// ValidateRequest validates legacy HMAC request, the signature covers only the timestamp.
 func (h *HMACAuth) ValidateRequest(timestamp, signature string) error {
 	if timestamp == "" || signature == "" {
 		return ErrMissingHeaders
 	}
+	if err := h.validate(timestamp); err != nil {
+		return err
+	}
+	return h.verify(timestamp, signature)
+}
+
+// ValidateSignedRequest validates request-bound HMAC signature.
+// The nonce is only checked for format, callers must reject reused nonces.
+func (h *HMACAuth) ValidateSignedRequest(req SignedRequest, signature string) error {
+	if req.Timestamp == "" || req.Nonce == "" || signature == "" {
+		return ErrMissingHeaders
+	}
+	if len(req.Nonce) < nonceMinLen || len(req.Nonce) > nonceMaxLen || strings.ContainsAny(req.Nonce, "\n:") {
+		return ErrInvalidNonce
+	}
+	if err := h.validate(req.Timestamp); err != nil {
+		return err
+	}
+	return h.verify(req.Canonical(), signature)
+}
+
+// NonceExpiresAt returns the time after which a request with the timestamp is rejected,
+// nonces have to be remembered until then.
+func NonceExpiresAt(timestamp string) (time.Time, error) {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrap(ErrTimestampParse, err.Error())
+	}
+	return time.Unix(ts+TimestampDelay, 0), nil
+}
+
+func (h *HMACAuth) validate(timestamp string) error {
 	if len(h.secret) == 0 {
 		return ErrNoDeviceToken
 	}
@@ -46,8 +137,11 @@ func (h *HMACAuth) ValidateRequest(timestamp, signature string) error {
 	if currentTime-ts > TimestampDelay || ts > currentTime+TimestampDelay {
 		return ErrTimestampExpired
 	}
+	return nil
+}
 
-	expectedSignature := h.GenerateSignature(timestamp)
+func (h *HMACAuth) verify(data, signature string) error {
+	expectedSignature := h.GenerateSignature(data)
 	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
 		return ErrInvalidSignature
 	}
@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultJWKSCacheTTL is how long keys from a JWKS file or endpoint are used before reloading.
+	DefaultJWKSCacheTTL = 10 * time.Minute
+
+	// jwksMinReload limits reloads for unknown kids, so forged tokens cannot hammer the source.
+	jwksMinReload   = 30 * time.Second
+	jwksLoadTimeout = 3 * time.Second
+	jwksMaxSize     = 1 << 20
+
+	minRSABits = 2048
+)
+
+// JWK is a public key in JSON Web Key format, RSA, EC P-256 and Ed25519 keys are supported.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// verificationKey is a public key with the only algorithm it may verify,
+// so a token cannot pick another algorithm for the same key.
+type verificationKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// NewJWK returns JWK of the public key.
+func NewJWK(kid string, public crypto.PublicKey) (JWK, error) {
+	key := JWK{Kid: kid, Use: "sig"}
+
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		key.Kty, key.Alg = "RSA", jwt.SigningMethodRS256.Alg()
+		key.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		key.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return JWK{}, ErrUnsupportedKey
+		}
+		key.Kty, key.Alg, key.Crv = "EC", jwt.SigningMethodES256.Alg(), "P-256"
+		key.X = base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, 32)))
+		key.Y = base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		key.Kty, key.Alg, key.Crv = "OKP", jwt.SigningMethodEdDSA.Alg(), "Ed25519"
+		key.X = base64.RawURLEncoding.EncodeToString(k)
+	default:
+		return JWK{}, ErrUnsupportedKey
+	}
+	return key, nil
+}
+
+// verificationKey parses the public key, the algorithm follows from the key type.
+func (k JWK) verificationKey() (verificationKey, error) {
+	if k.Use != "" && k.Use != "sig" {
+		return verificationKey{}, errors.Wrapf(ErrUnsupportedKey, "key %s is not for signatures", k.Kid)
+	}
+	var (
+		vk  verificationKey
+		err error
+	)
+	switch {
+	case k.Kty == "RSA":
+		vk.alg = jwt.SigningMethodRS256.Alg()
+		vk.key, err = rsaPublicKey(k.N, k.E)
+	case k.Kty == "EC" && k.Crv == "P-256":
+		vk.alg = jwt.SigningMethodES256.Alg()
+		vk.key, err = ecPublicKey(k.X, k.Y)
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		vk.alg = jwt.SigningMethodEdDSA.Alg()
+		vk.key, err = edPublicKey(k.X)
+	default:
+		return verificationKey{}, errors.Wrapf(ErrUnsupportedKey, "key %s has type %s %s", k.Kid, k.Kty, k.Crv)
+	}
+	if err != nil {
+		return verificationKey{}, errors.Wrapf(err, "invalid key %s", k.Kid)
+	}
+	if k.Alg != "" && k.Alg != vk.alg {
+		return verificationKey{}, errors.Wrapf(ErrUnsupportedKey, "key %s has algorithm %s", k.Kid, k.Alg)
+	}
+	return vk, nil
+}
+
+// parseJWKS parses JWKS document, every key must have a unique kid.
+func parseJWKS(data []byte) (JWKS, map[string]verificationKey, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return JWKS{}, nil, errors.Wrap(err, "failed to parse jwks")
+	}
+	keys := make(map[string]verificationKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kid == "" {
+			return JWKS{}, nil, ErrMissingKeyID
+		}
+		if _, ok := keys[k.Kid]; ok {
+			return JWKS{}, nil, errors.Errorf("duplicate key id %s", k.Kid)
+		}
+		vk, err := k.verificationKey()
+		if err != nil {
+			return JWKS{}, nil, err
+		}
+		keys[k.Kid] = vk
+	}
+	return set, keys, nil
+}
+
+// KeySet holds verification keys selected by kid. Keys from a file or an endpoint are cached for ttl
+// and reloaded earlier when a token refers to an unknown kid, which picks up newly rotated keys.
+type KeySet struct {
+	load func(ctx context.Context) ([]byte, error)
+	ttl  time.Duration
+
+	mu     sync.Mutex
+	set    JWKS
+	keys   map[string]verificationKey
+	loaded time.Time
+}
+
+// NewKeySet creates KeySet from source: inline JWKS JSON, http(s) URL or file path.
+// Inline keys are parsed at once, files and endpoints are loaded on first use.
+func NewKeySet(source string, ttl time.Duration) (*KeySet, error) {
+	source = strings.TrimSpace(source)
+	s := &KeySet{ttl: ttl}
+
+	switch {
+	case strings.HasPrefix(source, "{"):
+		set, keys, err := parseJWKS([]byte(source))
+		if err != nil {
+			return nil, err
+		}
+		s.set, s.keys = set, keys
+	case strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://"):
+		client := &http.Client{Timeout: jwksLoadTimeout}
+		s.load = func(ctx context.Context) ([]byte, error) {
+			return fetchJWKS(ctx, client, source)
+		}
+	case source != "":
+		s.load = func(_ context.Context) ([]byte, error) {
+			return os.ReadFile(source)
+		}
+	default:
+		return nil, errors.New("jwks source is empty")
+	}
+	return s, nil
+}
+
+// JWKS returns keys of the set for publishing.
+func (s *KeySet) JWKS(ctx context.Context) (JWKS, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(ctx, false); err != nil && s.keys == nil {
+		return JWKS{}, err
+	}
+	return s.set, nil
+}
+
+func (s *KeySet) key(ctx context.Context, kid string) (verificationKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, known := s.keys[kid]
+	// a failed reload keeps the cached keys, tokens of known keys stay valid while the source is down.
+	err := s.reload(ctx, !known)
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if err != nil {
+		return verificationKey{}, err
+	}
+	return verificationKey{}, ErrUnknownKeyID
+}
+
+// reload loads keys when the cache expired, unknown forces reload after jwksMinReload.
+// Failed loads are retried after jwksMinReload as well.
+func (s *KeySet) reload(ctx context.Context, unknown bool) error {
+	if s.load == nil {
+		return nil
+	}
+	age := time.Since(s.loaded)
+	if !s.loaded.IsZero() && (age < jwksMinReload || (age < s.ttl && !unknown && s.keys != nil)) {
+		return nil
+	}
+	data, err := s.load(ctx)
+	if err == nil {
+		var (
+			set  JWKS
+			keys map[string]verificationKey
+		)
+		if set, keys, err = parseJWKS(data); err == nil {
+			s.set, s.keys = set, keys
+		}
+	}
+	s.loaded = time.Now()
+	return errors.Wrap(err, "failed to load jwks")
+}
+
+func fetchJWKS(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, jwksMaxSize))
+}
+
+func rsaPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nb, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	eb, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(eb)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid rsa exponent")
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(exponent.Int64())}
+	if key.N.BitLen() < minRSABits {
+		return nil, errors.Errorf("rsa key is shorter than %d bits", minRSABits)
+	}
+	return key, nil
+}
+
+func ecPublicKey(x, y string) (*ecdsa.PublicKey, error) {
+	xb, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, err
+	}
+	yb, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil {
+		return nil, err
+	}
+	if len(xb) != 32 || len(yb) != 32 {
+		return nil, errors.New("invalid P-256 coordinates")
+	}
+	// ecdh checks the point is on the curve.
+	if _, err = ecdh.P256().NewPublicKey(append(append([]byte{4}, xb...), yb...)); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(xb), Y: new(big.Int).SetBytes(yb)}, nil
+}
+
+func edPublicKey(x string) (ed25519.PublicKey, error) {
+	xb, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, err
+	}
+	if len(xb) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid Ed25519 key size")
+	}
+	return ed25519.PublicKey(xb), nil
+}
@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey := testSigningKey(t, "rsa", "rsa-pkcs1")
+	ecKey := testSigningKey(t, "ec", "ec-sec1")
+	edKey := testSigningKey(t, "ed", "ed25519")
+	jwk := func(k *SigningKey) JWK {
+		key, err := k.JWK()
+		if err != nil {
+			t.Fatalf("JWK(%s) error = %v", k.ID, err)
+		}
+		return key
+	}
+	with := func(k JWK, change func(*JWK)) JWK {
+		change(&k)
+		return k
+	}
+
+	tests := []struct {
+		name    string
+		keys    []JWK
+		raw     string
+		wantAlg map[string]string
+		wantErr error
+	}{
+		{
+			name:    "all key types",
+			keys:    []JWK{jwk(rsaKey), jwk(ecKey), jwk(edKey)},
+			wantAlg: map[string]string{"rsa": "RS256", "ec": "ES256", "ed": "EdDSA"},
+		},
+		{
+			name:    "alg and use are optional",
+			keys:    []JWK{with(jwk(ecKey), func(k *JWK) { k.Alg, k.Use = "", "" })},
+			wantAlg: map[string]string{"ec": "ES256"},
+		},
+		{name: "empty set", raw: `{"keys":[]}`, wantAlg: map[string]string{}},
+		{name: "missing kid", keys: []JWK{with(jwk(ecKey), func(k *JWK) { k.Kid = "" })}, wantErr: ErrMissingKeyID},
+		{name: "encryption key", keys: []JWK{with(jwk(ecKey), func(k *JWK) { k.Use = "enc" })}, wantErr: ErrUnsupportedKey},
+		{name: "algorithm mismatch", keys: []JWK{with(jwk(ecKey), func(k *JWK) { k.Alg = "HS256" })}, wantErr: ErrUnsupportedKey},
+		{name: "unsupported curve", keys: []JWK{with(jwk(ecKey), func(k *JWK) { k.Crv = "P-384" })}, wantErr: ErrUnsupportedKey},
+		{name: "symmetric key", raw: `{"keys":[{"kty":"oct","kid":"hs","k":"c2VjcmV0"}]}`, wantErr: ErrUnsupportedKey},
+		{name: "duplicate kid", keys: []JWK{jwk(ecKey), with(jwk(edKey), func(k *JWK) { k.Kid = "ec" })}},
+		{name: "point off the curve", keys: []JWK{with(jwk(ecKey), func(k *JWK) { k.Y = k.X })}},
+		{name: "short rsa modulus", keys: []JWK{with(jwk(rsaKey), func(k *JWK) { k.N = base64.RawURLEncoding.EncodeToString([]byte{0xff, 0x01}) })}},
+		{name: "invalid rsa exponent", keys: []JWK{with(jwk(rsaKey), func(k *JWK) { k.E = "AQ" })}},
+		{name: "short ed25519 key", keys: []JWK{with(jwk(edKey), func(k *JWK) { k.X = k.X[:10] })}},
+		{name: "not base64url", keys: []JWK{with(jwk(edKey), func(k *JWK) { k.X = "***" })}},
+		{name: "not json", raw: `keys`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.raw)
+			if tt.raw == "" {
+				var err error
+				if data, err = json.Marshal(JWKS{Keys: tt.keys}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, keys, err := parseJWKS(data)
+			if tt.wantAlg == nil {
+				if err == nil {
+					t.Fatal("parseJWKS() accepted the set")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("parseJWKS() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJWKS() error = %v", err)
+			}
+			if len(keys) != len(tt.wantAlg) {
+				t.Fatalf("parseJWKS() = %d keys, want %d", len(keys), len(tt.wantAlg))
+			}
+			for kid, alg := range tt.wantAlg {
+				if keys[kid].alg != alg {
+					t.Errorf("key %s alg = %s, want %s", kid, keys[kid].alg, alg)
+				}
+			}
+		})
+	}
+}
+
+func TestKeySetReload(t *testing.T) {
+	ctx := context.Background()
+	key1 := testSigningKey(t, "key-1", "ec-sec1")
+	key2 := testSigningKey(t, "key-2", "ed25519")
+
+	type step struct {
+		name    string
+		age     time.Duration // time since the last load before the step, 0 keeps it
+		source  string        // JWKS served from the step on, empty keeps it
+		fail    bool          // the source fails from the step on
+		kid     string
+		wantErr error
+		loads   int // loads after the step
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "keys are cached for ttl", steps: []step{
+			{name: "first use loads", source: testJWKS(t, key1), kid: "key-1", loads: 1},
+			{name: "cached", kid: "key-1", loads: 1},
+			{name: "cached after min reload", age: jwksMinReload + time.Second, kid: "key-1", loads: 1},
+			{name: "expired", age: time.Hour, kid: "key-1", loads: 2},
+		}},
+		{name: "unknown kid reloads once per min reload", steps: []step{
+			{name: "first use loads", source: testJWKS(t, key1), kid: "key-1", loads: 1},
+			{name: "throttled", source: testJWKS(t, key1, key2), kid: "key-2", wantErr: ErrUnknownKeyID, loads: 1},
+			{name: "still throttled", age: jwksMinReload / 2, kid: "key-2", wantErr: ErrUnknownKeyID, loads: 1},
+			{name: "reloaded", age: jwksMinReload, kid: "key-2", loads: 2},
+			{name: "known now", kid: "key-2", loads: 2},
+			{name: "unknown again throttled", kid: "key-3", wantErr: ErrUnknownKeyID, loads: 2},
+		}},
+		{name: "failed loads keep cached keys and are throttled", steps: []step{
+			{name: "first use loads", source: testJWKS(t, key1), kid: "key-1", loads: 1},
+			{name: "source down", age: time.Hour, fail: true, kid: "key-1", loads: 2},
+			{name: "failure throttled", kid: "key-1", loads: 2},
+			{name: "unknown kid fails", age: jwksMinReload, kid: "key-2", wantErr: errLoadFailed, loads: 3},
+			{name: "unknown kid throttled", kid: "key-2", wantErr: ErrUnknownKeyID, loads: 3},
+		}},
+		{name: "failed first load is throttled", steps: []step{
+			{name: "source down", fail: true, kid: "key-1", wantErr: errLoadFailed, loads: 1},
+			{name: "throttled", kid: "key-1", wantErr: ErrUnknownKeyID, loads: 1},
+			{name: "retried", age: jwksMinReload, kid: "key-1", wantErr: errLoadFailed, loads: 2},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				source string
+				fail   bool
+				loads  int
+			)
+			set := &KeySet{ttl: 10 * time.Minute, load: func(context.Context) ([]byte, error) {
+				loads++
+				if fail {
+					return nil, errLoadFailed
+				}
+				return []byte(source), nil
+			}}
+			for _, s := range tt.steps {
+				if s.age != 0 {
+					set.loaded = time.Now().Add(-s.age)
+				}
+				if s.source != "" {
+					source = s.source
+				}
+				fail = fail || s.fail
+
+				_, err := set.key(ctx, s.kid)
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("%s: key(%s) error = %v, want %v", s.name, s.kid, err, s.wantErr)
+				}
+				if loads != s.loads {
+					t.Fatalf("%s: loads = %d, want %d", s.name, loads, s.loads)
+				}
+			}
+		})
+	}
+}
+
+var errLoadFailed = errors.New("load failed")
+
+func TestNewKeySetSources(t *testing.T) {
+	ctx := context.Background()
+	key := testSigningKey(t, "key-1", "ec-sec1")
+	jwks := testJWKS(t, key)
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path != "/jwks.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(jwks))
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, []byte(jwks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		source    string
+		wantErr   bool
+		wantKeyOK bool
+	}{
+		{name: "inline", source: " " + jwks + "\n", wantKeyOK: true},
+		{name: "url", source: server.URL + "/jwks.json", wantKeyOK: true},
+		{name: "file", source: file, wantKeyOK: true},
+		{name: "url not found", source: server.URL + "/missing"},
+		{name: "missing file", source: filepath.Join(t.TempDir(), "missing.json")},
+		{name: "invalid inline", source: `{"keys":[{"kty":"oct","kid":"k"}]}`, wantErr: true},
+		{name: "empty", source: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewKeySet(tt.source, time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewKeySet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if _, err = set.key(ctx, key.ID); (err == nil) != tt.wantKeyOK {
+				t.Fatalf("key() error = %v, want ok %v", err, tt.wantKeyOK)
+			}
+			if !tt.wantKeyOK {
+				return
+			}
+			published, err := set.JWKS(ctx)
+			if err != nil {
+				t.Fatalf("JWKS() error = %v", err)
+			}
+			if len(published.Keys) != 1 || published.Keys[0].Kid != key.ID {
+				t.Errorf("JWKS() = %+v", published)
+			}
+		})
+	}
+	// the url source was fetched once by key and served from cache to JWKS, plus once for the missing path.
+	if got := hits.Load(); got != 2 {
+		t.Errorf("server hits = %d, want 2", got)
+	}
+}
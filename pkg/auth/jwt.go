@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -8,54 +9,155 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Claims represents JWT claims structure
+// Claims represents JWT claims structure, the registered "jti" claim (StandardClaims.Id)
+// carries ID of the login session the token is issued for.
 type Claims struct {
 	Identifier int  `json:"identifier"`
 	Role       Role `json:"role"`
 	jwt.StandardClaims
 }
 
-// JWTAuth handles JWT-specific authentication
+// JWTAuth handles JWT-specific authentication.
+// Tokens are signed with the signing key when it is set and with the shared secret (HS256) otherwise.
+// HMAC tokens are accepted while the secret is set, asymmetric tokens are verified by the key selected by kid.
 type JWTAuth struct {
 	secret []byte
+	signer *SigningKey
+	keys   *KeySet
 }
 
-// NewJWTAuth creates new JWT authenticator instance
+// NewJWTAuth creates new JWT authenticator instance, empty secret disables HMAC tokens.
 func NewJWTAuth(secret string) *JWTAuth {
 	return &JWTAuth{
 		secret: []byte(secret),
 	}
 }
 
+// WithSigningKey makes new tokens signed with the key, its own tokens are verified without KeySet.
+func (j *JWTAuth) WithSigningKey(key *SigningKey) *JWTAuth {
+	j.signer = key
+	return j
+}
+
+// WithKeySet sets verification keys of asymmetric tokens.
+func (j *JWTAuth) WithKeySet(keys *KeySet) *JWTAuth {
+	j.keys = keys
+	return j
+}
+
 // ValidateToken validates JWT token and returns claims
-func (j *JWTAuth) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(strings.TrimPrefix(tokenString, "Bearer "), &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrUnexpectedSigningMethod
-		}
-		return j.secret, nil
+func (j *JWTAuth) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
+	parser := jwt.Parser{ValidMethods: j.validMethods()}
+	token, err := parser.ParseWithClaims(strings.TrimPrefix(tokenString, "Bearer "), &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return j.verificationKey(ctx, token)
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse token")
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidTokenClaims
 	}
-	return nil, ErrInvalidTokenClaims
+	if !UserRoleIsValid(claims.Role) {
+		return nil, ErrInvalidTokenRole
+	}
+	return claims, nil
 }
 
-// GenerateToken creates new JWT token with provided claims
-func (j *JWTAuth) GenerateToken(identifier int, role Role, expiresIn time.Duration) (string, error) {
+// GenerateToken creates new JWT token with provided claims,
+// tokenID refers to the login session the token is issued for.
+func (j *JWTAuth) GenerateToken(identifier int, role Role, tokenID string, expiresIn time.Duration) (string, error) {
+	if !UserRoleIsValid(role) {
+		return "", ErrInvalidTokenRole
+	}
 	claims := Claims{
 		Identifier: identifier,
 		Role:       role,
 
 		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
 			ExpiresAt: time.Now().Add(expiresIn).Unix(),
 			IssuedAt:  time.Now().Unix(),
 		},
 	}
+	if j.signer != nil {
+		token := jwt.NewWithClaims(j.signer.method, claims)
+		token.Header["kid"] = j.signer.ID
+		return token.SignedString(j.signer.key)
+	}
+	if len(j.secret) == 0 {
+		return "", ErrNoSigningKey
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(j.secret)
 }
+
+// PublicKeys returns JWKS with verification keys and the public signing key,
+// other services verify tokens with it without holding any secret.
+func (j *JWTAuth) PublicKeys(ctx context.Context) (JWKS, error) {
+	set := JWKS{Keys: []JWK{}}
+	if j.keys != nil {
+		keys, err := j.keys.JWKS(ctx)
+		if err != nil {
+			return JWKS{}, err
+		}
+		set.Keys = append(set.Keys, keys.Keys...)
+	}
+	if j.signer == nil {
+		return set, nil
+	}
+	for _, k := range set.Keys {
+		if k.Kid == j.signer.ID {
+			return set, nil
+		}
+	}
+	key, err := j.signer.JWK()
+	if err != nil {
+		return JWKS{}, err
+	}
+	set.Keys = append(set.Keys, key)
+	return set, nil
+}
+
+// verificationKey selects the key by token kid, the key defines the only accepted algorithm,
+// so a token signed with HMAC over a public key is rejected.
+func (j *JWTAuth) verificationKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if len(j.secret) == 0 {
+			return nil, ErrUnexpectedSigningMethod
+		}
+		return j.secret, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrMissingKeyID
+	}
+
+	var (
+		key verificationKey
+		err error
+	)
+	switch {
+	case j.signer != nil && j.signer.ID == kid:
+		key = j.signer.public
+	case j.keys != nil:
+		if key, err = j.keys.key(ctx, kid); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnknownKeyID
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, ErrUnexpectedSigningMethod
+	}
+	return key.key, nil
+}
+
+func (j *JWTAuth) validMethods() []string {
+	methods := []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	if len(j.secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg(), jwt.SigningMethodHS384.Alg(), jwt.SigningMethodHS512.Alg())
+	}
+	return methods
+}
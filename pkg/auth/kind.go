@@ -23,3 +23,12 @@ func KindIsValid(k Kind) bool {
 	_, ok := kindNames[k]
 	return ok
 }
+
+func ParseKind(kind string) (Kind, bool) {
+	for k, name := range kindNames {
+		if name == kind {
+			return k, true
+		}
+	}
+	return 0, false
+}
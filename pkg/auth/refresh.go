@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"encoding/hex"
+	"strings"
+
+	sha256 "github.com/minio/sha256-simd"
+)
+
+const (
+	// RefreshTokenSeparator separates token ID and secret of a refresh token.
+	RefreshTokenSeparator = "."
+
+	tokenIDBytes       = 16
+	refreshSecretBytes = 32
+)
+
+// NewTokenID generates identifier of a login session, access tokens carry it as "jti" claim.
+func NewTokenID() (string, error) {
+	return randomHex(tokenIDBytes)
+}
+
+// NewRefreshToken generates refresh token of the session and the hash to store,
+// every refresh replaces the secret while the token ID stays the same.
+func NewRefreshToken(tokenID string) (token, hash string, err error) {
+	secret, err := randomHex(refreshSecretBytes)
+	if err != nil {
+		return "", "", err
+	}
+	return tokenID + RefreshTokenSeparator + secret, RefreshTokenHash(secret), nil
+}
+
+// ParseRefreshToken splits refresh token into token ID and secret, ok is false for other formats.
+func ParseRefreshToken(token string) (tokenID, secret string, ok bool) {
+	tokenID, secret, ok = strings.Cut(token, RefreshTokenSeparator)
+	if !ok || len(tokenID) != 2*tokenIDBytes || len(secret) != 2*refreshSecretBytes {
+		return "", "", false
+	}
+	return tokenID, secret, true
+}
+
+// RefreshTokenHash returns hex encoded SHA-256 of the refresh token secret.
+func RefreshTokenHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -41,6 +41,18 @@ func ParseRole(role string) (Role, bool) {
 	return Guest, false
 }
 
+// RoleIsValid reports whether the role is known.
+func RoleIsValid(r Role) bool {
+	_, ok := RoleNames[r]
+	return ok
+}
+
+// UserRoleIsValid reports whether the role may be claimed by a user token, User and above.
+// Device is reserved for signed device requests and Guest for public routes.
+func UserRoleIsValid(r Role) bool {
+	return RoleIsValid(r) && r >= User
+}
+
 func GetPermissionLevel(role Role) int {
 	if level, exists := RolePermissions[role]; exists {
 		return level
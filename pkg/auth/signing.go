@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+)
+
+// SigningKey is a private key which signs JWTs, ID is sent as "kid" header
+// and must match the key ID of its public key in the JWKS of verifiers.
+type SigningKey struct {
+	ID string
+
+	method jwt.SigningMethod
+	key    crypto.PrivateKey
+	public verificationKey
+}
+
+// ParseSigningKey parses PEM private key, the algorithm follows from the key type:
+// RSA keys sign RS256, EC P-256 keys sign ES256 and Ed25519 keys sign EdDSA.
+func ParseSigningKey(id string, pemData []byte) (*SigningKey, error) {
+	if id == "" {
+		return nil, ErrMissingKeyID
+	}
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+	var (
+		key crypto.PrivateKey
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedKey, "pem block %s", block.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse signing key")
+	}
+
+	s := &SigningKey{ID: id, key: key}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSABits {
+			return nil, errors.Errorf("rsa key is shorter than %d bits", minRSABits)
+		}
+		s.method, s.public.key = jwt.SigningMethodRS256, &k.PublicKey
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.Wrap(ErrUnsupportedKey, "only P-256 curve is supported")
+		}
+		s.method, s.public.key = jwt.SigningMethodES256, &k.PublicKey
+	case ed25519.PrivateKey:
+		s.method, s.public.key = jwt.SigningMethodEdDSA, k.Public()
+	default:
+		return nil, ErrUnsupportedKey
+	}
+	s.public.alg = s.method.Alg()
+	return s, nil
+}
+
+// Algorithm returns JWT algorithm of the key, e.g. "RS256".
+func (k *SigningKey) Algorithm() string {
+	return k.method.Alg()
+}
+
+// JWK returns public part of the key for publishing in JWKS.
+func (k *SigningKey) JWK() (JWK, error) {
+	return NewJWK(k.ID, k.public.key)
+}
@@ -0,0 +1,343 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	testPEMOnce sync.Once
+	testPEMs    map[string][]byte
+)
+
+// testPEM returns PEM private key of the kind, keys are generated once since RSA generation is slow.
+func testPEM(t *testing.T, kind string) []byte {
+	t.Helper()
+	testPEMOnce.Do(func() {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		shortRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			panic(err)
+		}
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		otherECKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+
+		encode := func(blockType string, der []byte, err error) []byte {
+			if err != nil {
+				panic(err)
+			}
+			return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+		}
+		pkcs8 := func(key any) []byte {
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			return encode("PRIVATE KEY", der, err)
+		}
+		sec1 := func(key *ecdsa.PrivateKey) []byte {
+			der, err := x509.MarshalECPrivateKey(key)
+			return encode("EC PRIVATE KEY", der, err)
+		}
+		rsaPublicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		testPEMs = map[string][]byte{
+			"rsa-pkcs1": encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), nil),
+			"rsa-pkcs8": pkcs8(rsaKey),
+			"rsa-1024":  encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(shortRSAKey), nil),
+			"ec-sec1":   sec1(ecKey),
+			"ec-pkcs8":  pkcs8(ecKey),
+			"ec-other":  sec1(otherECKey),
+			"ec-p384":   sec1(p384Key),
+			"ed25519":   pkcs8(edKey),
+			"public":    encode("PUBLIC KEY", rsaPublicDER, err),
+		}
+	})
+	data, ok := testPEMs[kind]
+	if !ok {
+		t.Fatalf("unknown test key %s", kind)
+	}
+	return data
+}
+
+func testSigningKey(t *testing.T, id, kind string) *SigningKey {
+	t.Helper()
+	key, err := ParseSigningKey(id, testPEM(t, kind))
+	if err != nil {
+		t.Fatalf("ParseSigningKey(%s) error = %v", kind, err)
+	}
+	return key
+}
+
+// testJWKS returns inline JWKS source with public keys of the signing keys.
+func testJWKS(t *testing.T, keys ...*SigningKey) string {
+	t.Helper()
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range keys {
+		jwk, err := k.JWK()
+		if err != nil {
+			t.Fatalf("JWK(%s) error = %v", k.ID, err)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	return string(data)
+}
+
+func testVerifier(t *testing.T, secret string, keys ...*SigningKey) *JWTAuth {
+	t.Helper()
+	set, err := NewKeySet(testJWKS(t, keys...), time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+	return NewJWTAuth(secret).WithKeySet(set)
+}
+
+// tokenError returns the error of the key function, jwt wraps it into ValidationError.
+func tokenError(err error) error {
+	var vErr *jwt.ValidationError
+	if errors.As(err, &vErr) && vErr.Inner != nil {
+		return vErr.Inner
+	}
+	return err
+}
+
+func TestParseSigningKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		kind    string
+		pem     []byte
+		wantAlg string
+		wantErr bool
+	}{
+		{name: "rsa pkcs1", id: "k", kind: "rsa-pkcs1", wantAlg: "RS256"},
+		{name: "rsa pkcs8", id: "k", kind: "rsa-pkcs8", wantAlg: "RS256"},
+		{name: "ec sec1", id: "k", kind: "ec-sec1", wantAlg: "ES256"},
+		{name: "ec pkcs8", id: "k", kind: "ec-pkcs8", wantAlg: "ES256"},
+		{name: "ed25519", id: "k", kind: "ed25519", wantAlg: "EdDSA"},
+		{name: "missing id", kind: "ed25519", wantErr: true},
+		{name: "short rsa", id: "k", kind: "rsa-1024", wantErr: true},
+		{name: "p384", id: "k", kind: "ec-p384", wantErr: true},
+		{name: "public key", id: "k", kind: "public", wantErr: true},
+		{name: "not pem", id: "k", pem: []byte("secret"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.pem
+			if tt.kind != "" {
+				data = testPEM(t, tt.kind)
+			}
+			key, err := ParseSigningKey(tt.id, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSigningKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if key.Algorithm() != tt.wantAlg {
+				t.Errorf("Algorithm() = %s, want %s", key.Algorithm(), tt.wantAlg)
+			}
+			jwk, err := key.JWK()
+			if err != nil {
+				t.Fatalf("JWK() error = %v", err)
+			}
+			if jwk.Kid != tt.id || jwk.Alg != tt.wantAlg || jwk.Use != "sig" {
+				t.Errorf("JWK() = %+v, want kid %s and alg %s", jwk, tt.id, tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestAsymmetricTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+	}{
+		{name: "RS256", kind: "rsa-pkcs8"},
+		{name: "ES256", kind: "ec-sec1"},
+		{name: "EdDSA", kind: "ed25519"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := testSigningKey(t, "key-"+tt.name, tt.kind)
+			signer := NewJWTAuth("").WithSigningKey(key)
+			token, err := signer.GenerateToken(7, Manager, "session", time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified() error = %v", err)
+			}
+			if parsed.Header["alg"] != tt.name || parsed.Header["kid"] != key.ID {
+				t.Errorf("header = %v, want alg %s and kid %s", parsed.Header, tt.name, key.ID)
+			}
+
+			// the signer verifies its own tokens, other services verify with the published key.
+			for name, verifier := range map[string]*JWTAuth{"signer": signer, "jwks": testVerifier(t, "", key)} {
+				claims, err := verifier.ValidateToken(context.Background(), token)
+				if err != nil {
+					t.Fatalf("%s: ValidateToken() error = %v", name, err)
+				}
+				if claims.Identifier != 7 || claims.Role != Manager || claims.Id != "session" {
+					t.Errorf("%s: claims = %+v", name, claims)
+				}
+			}
+
+			tampered := token[:len(token)-4] + "AAAA"
+			if _, err = testVerifier(t, "", key).ValidateToken(context.Background(), tampered); err == nil {
+				t.Error("tampered token is accepted")
+			}
+		})
+	}
+}
+
+func TestVerificationKeySelection(t *testing.T) {
+	ecKey := testSigningKey(t, "ec", "ec-sec1")
+	edKey := testSigningKey(t, "ed", "ed25519")
+	otherEC := testSigningKey(t, "other", "ec-other")
+
+	sign := func(t *testing.T, method jwt.SigningMethod, kid string, key any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, Claims{
+			Identifier:     1,
+			Role:           User,
+			StandardClaims: jwt.StandardClaims{Id: "session", ExpiresAt: time.Now().Add(time.Minute).Unix()},
+		})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return s
+	}
+	ecPublic, err := x509.MarshalPKIXPublicKey(ecKey.public.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		token   func(t *testing.T) string
+		wantErr error
+		invalid bool
+	}{
+		{name: "es256 by kid", token: func(t *testing.T) string { return sign(t, jwt.SigningMethodES256, "ec", ecKey.key) }},
+		{name: "eddsa by kid", token: func(t *testing.T) string { return sign(t, jwt.SigningMethodEdDSA, "ed", edKey.key) }},
+		{name: "signed by another key", token: func(t *testing.T) string { return sign(t, jwt.SigningMethodES256, "ec", otherEC.key) }, invalid: true},
+		{name: "unknown kid", token: func(t *testing.T) string { return sign(t, jwt.SigningMethodES256, "other", otherEC.key) }, wantErr: ErrUnknownKeyID},
+		{name: "missing kid", token: func(t *testing.T) string { return sign(t, jwt.SigningMethodES256, "", ecKey.key) }, wantErr: ErrMissingKeyID},
+		{name: "algorithm of another key", token: func(t *testing.T) string { return sign(t, jwt.SigningMethodES256, "ed", ecKey.key) }, wantErr: ErrUnexpectedSigningMethod},
+		{name: "hs256 over public key", token: func(t *testing.T) string { return sign(t, jwt.SigningMethodHS256, "ec", ecPublic) }, invalid: true},
+		{name: "hs256 without secret", token: func(t *testing.T) string { return sign(t, jwt.SigningMethodHS256, "", []byte(testSecret)) }, invalid: true},
+		{name: "hs256 with secret", secret: testSecret, token: func(t *testing.T) string { return sign(t, jwt.SigningMethodHS256, "", []byte(testSecret)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testVerifier(t, tt.secret, ecKey, edKey).ValidateToken(context.Background(), tt.token(t))
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(tokenError(err), tt.wantErr) {
+					t.Errorf("ValidateToken() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.invalid:
+				if err == nil {
+					t.Error("ValidateToken() accepted the token")
+				}
+			case err != nil:
+				t.Errorf("ValidateToken() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestKeyRotation follows the rotation documented in api-auth: publish the new key, switch the signer,
+// then drop the old key once its tokens expired.
+func TestKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	oldKey := testSigningKey(t, "key-1", "ec-sec1")
+	newKey := testSigningKey(t, "key-2", "ed25519")
+
+	jwks := testJWKS(t, oldKey)
+	set := &KeySet{ttl: time.Hour, load: func(context.Context) ([]byte, error) { return []byte(jwks), nil }}
+	verifier := NewJWTAuth("").WithKeySet(set)
+	signer := NewJWTAuth("").WithSigningKey(oldKey)
+
+	oldToken, err := signer.GenerateToken(1, User, "session", time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err = verifier.ValidateToken(ctx, oldToken); err != nil {
+		t.Fatalf("old token: %v", err)
+	}
+
+	// 1. the new public key is published next to the old one.
+	jwks = testJWKS(t, oldKey, newKey)
+	// 2. the signer switches, its JWKS holds both keys.
+	signer = NewJWTAuth("").WithSigningKey(newKey).WithKeySet(set)
+	newToken, err := signer.GenerateToken(1, User, "session", time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	// the verifier loaded the keys recently, the unknown kid is picked up after jwksMinReload.
+	if _, err = verifier.ValidateToken(ctx, newToken); !errors.Is(tokenError(err), ErrUnknownKeyID) {
+		t.Fatalf("new token before reload: error = %v, want %v", err, ErrUnknownKeyID)
+	}
+	set.loaded = time.Now().Add(-jwksMinReload)
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err = verifier.ValidateToken(ctx, token); err != nil {
+			t.Fatalf("%s token after publishing: %v", name, err)
+		}
+	}
+	published, err := signer.PublicKeys(ctx)
+	if err != nil {
+		t.Fatalf("PublicKeys() error = %v", err)
+	}
+	if len(published.Keys) != 2 {
+		t.Errorf("PublicKeys() = %d keys, want 2", len(published.Keys))
+	}
+
+	// 3. the old key is removed, its tokens are rejected after the next reload.
+	jwks = testJWKS(t, newKey)
+	set.loaded = time.Now().Add(-time.Hour)
+	if _, err = verifier.ValidateToken(ctx, newToken); err != nil {
+		t.Fatalf("new token after removing the old key: %v", err)
+	}
+	if _, err = verifier.ValidateToken(ctx, oldToken); !errors.Is(tokenError(err), ErrUnknownKeyID) {
+		t.Fatalf("old token after removal: error = %v, want %v", err, ErrUnknownKeyID)
+	}
+}
@@ -3,6 +3,7 @@ package cloud
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
@@ -17,6 +18,13 @@ var (
 	ErrDynamoEmptyKey   = errors.New("empty key")
 )
 
+const (
+	// batchWriteSize is the maximum number of requests in one BatchWriteItem call.
+	batchWriteSize = 25
+	// batchWriteRetries limits resending of unprocessed items.
+	batchWriteRetries = 5
+)
+
 // QueryInput represents the input for a DynamoDB query.
 type QueryInput struct {
 	IndexName         string
@@ -76,7 +84,6 @@ func (d *Dynamo) BuildQueryInput(input QueryInput) (*dynamodb.QueryInput, error)
 	}
 
 	queryInput := &dynamodb.QueryInput{
-		IndexName:                 &input.IndexName,
 		KeyConditionExpression:    expr.KeyCondition(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
@@ -84,6 +91,9 @@ func (d *Dynamo) BuildQueryInput(input QueryInput) (*dynamodb.QueryInput, error)
 		ScanIndexForward:          &input.ScanForward,
 		ExclusiveStartKey:         input.ExclusiveStartKey,
 	}
+	if input.IndexName != "" {
+		queryInput.IndexName = aws.String(input.IndexName)
+	}
 	if expr.Filter() != nil {
 		queryInput.FilterExpression = expr.Filter()
 	}
@@ -229,3 +239,154 @@ func (d *Dynamo) Scan(ctx context.Context, table string, input *dynamodb.ScanInp
 	}
 	return result, nil
 }
+
+// BatchWrite puts and deletes items in chunks of 25, unprocessed items are resent with backoff.
+func (d *Dynamo) BatchWrite(ctx context.Context, table string, puts []map[string]types.AttributeValue, deletes []map[string]types.AttributeValue) error {
+	if err := validateTable(table); err != nil {
+		return err
+	}
+
+	requests := make([]types.WriteRequest, 0, len(puts)+len(deletes))
+	for _, key := range deletes {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+	}
+	for _, item := range puts {
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	for start := 0; start < len(requests); start += batchWriteSize {
+		end := min(start+batchWriteSize, len(requests))
+		if err := d.batchWrite(ctx, table, requests[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dynamo) batchWrite(ctx context.Context, table string, requests []types.WriteRequest) error {
+	pending := map[string][]types.WriteRequest{table: requests}
+	for attempt := 0; ; attempt++ {
+		result, err := d.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+		if err != nil {
+			return errors.Wrap(err, "failed to batch write items")
+		}
+		if len(result.UnprocessedItems[table]) == 0 {
+			return nil
+		}
+		if attempt == batchWriteRetries {
+			return errors.Errorf("failed to batch write %d items: retries exhausted", len(result.UnprocessedItems[table]))
+		}
+		pending = result.UnprocessedItems
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(50<<attempt) * time.Millisecond):
+		}
+	}
+}
+
+// TransactWriteItem is one operation of TransactWrite, set Item for put,
+// Key for delete, or Key with Update for update.
+type TransactWriteItem struct {
+	Table     string
+	Item      map[string]types.AttributeValue
+	Key       map[string]types.AttributeValue
+	Update    *expression.UpdateBuilder
+	Condition expression.ConditionBuilder
+}
+
+// TransactWrite applies all operations atomically, either every operation succeeds or none.
+func (d *Dynamo) TransactWrite(ctx context.Context, items []TransactWriteItem) error {
+	transactItems := make([]types.TransactWriteItem, 0, len(items))
+	for i, item := range items {
+		if err := validateTable(item.Table); err != nil {
+			return err
+		}
+
+		builder := expression.NewBuilder()
+		hasExpression := false
+		if item.Update != nil {
+			builder = builder.WithUpdate(*item.Update)
+			hasExpression = true
+		}
+		if item.Condition.IsSet() {
+			builder = builder.WithCondition(item.Condition)
+			hasExpression = true
+		}
+		var expr expression.Expression
+		if hasExpression {
+			var err error
+			if expr, err = builder.Build(); err != nil {
+				return errors.Wrapf(err, "failed to build expression of transaction item %d", i)
+			}
+		}
+
+		switch {
+		case item.Item != nil:
+			transactItems = append(transactItems, types.TransactWriteItem{Put: &types.Put{
+				TableName:                 aws.String(item.Table),
+				Item:                      item.Item,
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			}})
+		case item.Update != nil:
+			if err := validateKey(item.Key); err != nil {
+				return err
+			}
+			transactItems = append(transactItems, types.TransactWriteItem{Update: &types.Update{
+				TableName:                 aws.String(item.Table),
+				Key:                       item.Key,
+				UpdateExpression:          expr.Update(),
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			}})
+		default:
+			if err := validateKey(item.Key); err != nil {
+				return err
+			}
+			transactItems = append(transactItems, types.TransactWriteItem{Delete: &types.Delete{
+				TableName:                 aws.String(item.Table),
+				Key:                       item.Key,
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			}})
+		}
+	}
+
+	_, err := d.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: transactItems})
+	if err != nil {
+		return errors.Wrap(err, "failed to execute transaction")
+	}
+	return nil
+}
+
+// IsTransactConditionFailed reports whether the transaction was canceled by the failed condition
+// of the operation at index.
+func IsTransactConditionFailed(err error, index int) bool {
+	var canceledErr *types.TransactionCanceledException
+	if !errors.As(err, &canceledErr) || index >= len(canceledErr.CancellationReasons) {
+		return false
+	}
+	return aws.ToString(canceledErr.CancellationReasons[index].Code) == "ConditionalCheckFailed"
+}
+
+// IsConditionFailed reports whether the write was rejected by its condition expression,
+// including transactions canceled because of a failed condition.
+func IsConditionFailed(err error) bool {
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return true
+	}
+	var canceledErr *types.TransactionCanceledException
+	if errors.As(err, &canceledErr) {
+		for _, reason := range canceledErr.CancellationReasons {
+			if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+				return true
+			}
+		}
+	}
+	return false
+}
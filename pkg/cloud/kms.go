@@ -0,0 +1,62 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+var (
+	ErrKmsEmptyKey       = errors.New("empty kms key id")
+	ErrKmsEmptyPlaintext = errors.New("empty plaintext")
+)
+
+// Kms represents a KMS client for encrypting small secrets.
+type Kms struct {
+	client *kms.Client
+}
+
+// NewKms creates a new instance of KMS client.
+func NewKms(cfg aws.Config) *Kms {
+	return &Kms{
+		client: kms.NewFromConfig(cfg),
+	}
+}
+
+// Encrypt encrypts plaintext up to 4 KB with the key, encryptionContext must be passed to Decrypt unchanged.
+func (k *Kms) Encrypt(ctx context.Context, keyID string, plaintext []byte, encryptionContext map[string]string) ([]byte, error) {
+	if keyID == "" {
+		return nil, ErrKmsEmptyKey
+	}
+	if len(plaintext) == 0 {
+		return nil, ErrKmsEmptyPlaintext
+	}
+	result, err := k.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:             aws.String(keyID),
+		Plaintext:         plaintext,
+		EncryptionContext: encryptionContext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	return result.CiphertextBlob, nil
+}
+
+// Decrypt decrypts ciphertext made by Encrypt with the same key and encryption context.
+func (k *Kms) Decrypt(ctx context.Context, keyID string, ciphertext []byte, encryptionContext map[string]string) ([]byte, error) {
+	if keyID == "" {
+		return nil, ErrKmsEmptyKey
+	}
+	result, err := k.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(keyID),
+		CiphertextBlob:    ciphertext,
+		EncryptionContext: encryptionContext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return result.Plaintext, nil
+}
@@ -0,0 +1,46 @@
+// Package history names immutable versions of converted dictionary files.
+//
+// Every conversion with new content gets the next version number, the file is stored
+// in the dictionary bucket under its own key and never overwritten.
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var objectPattern = regexp.MustCompile(`^([0-9a-f]{32})\.(.+)\.v([1-9][0-9]*)\.csv$`)
+
+// Key returns hash key of the versions of the dictionary in the subcategory.
+func Key(id, subcategory string) string {
+	return id + "#" + subcategory
+}
+
+// Object returns key of the version file in the dictionary bucket,
+// it is a valid identifier for download urls.
+func Object(id, subcategory string, number int) string {
+	return fmt.Sprintf("%s.%s.v%d.csv", id, subcategory, number)
+}
+
+// Parse returns dictionary id, subcategory and version number of the version file key,
+// ok is false for keys not produced by Object.
+func Parse(object string) (id, subcategory string, number int, ok bool) {
+	match := objectPattern.FindStringSubmatch(object)
+	if match == nil {
+		return "", "", 0, false
+	}
+	number, err := strconv.Atoi(match[3])
+	if err != nil {
+		return "", "", 0, false
+	}
+	return match[1], match[2], number, true
+}
+
+// Checksum returns hex encoded SHA-256 of the file content.
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
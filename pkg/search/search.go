@@ -0,0 +1,110 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MinTokenLen is the minimal token length in runes, shorter words are not indexed.
+	MinTokenLen = 2
+	// MaxTokenLen is the maximal token length in runes, longer words are truncated.
+	MaxTokenLen = 32
+	// PrefixLen is the length of the index partition prefix, query tokens are at least that long.
+	PrefixLen = MinTokenLen
+)
+
+// Field weights, a match in the name is worth more than a match in the description.
+const (
+	WeightName        = 4
+	WeightTopic       = 3
+	WeightAuthor      = 2
+	WeightDescription = 1
+)
+
+const (
+	// prefixPenalty scales relevance of a prefix match compared to the exact token match.
+	prefixPenalty = 0.5
+	// ratingBoost scales the influence of the dictionary rating on the rank.
+	ratingBoost = 0.25
+)
+
+// Document is the searchable text of a dictionary.
+type Document struct {
+	Name        string
+	Topic       string
+	Author      string
+	Description string
+}
+
+// Terms returns weight of every token of the document, weights of a token found in several fields are summed.
+func (d Document) Terms() map[string]int {
+	terms := make(map[string]int)
+	for _, field := range []struct {
+		text   string
+		weight int
+	}{
+		{d.Name, WeightName},
+		{d.Topic, WeightTopic},
+		{d.Author, WeightAuthor},
+		{d.Description, WeightDescription},
+	} {
+		for _, token := range Tokenize(field.text) {
+			terms[token] += field.weight
+		}
+	}
+	return terms
+}
+
+// Tokenize splits text into unique lower-case tokens of letters and digits in order of appearance.
+func Tokenize(text string) []string {
+	var (
+		tokens []string
+		seen   = make(map[string]struct{})
+	)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if utf8.RuneCountInString(word) < MinTokenLen {
+			continue
+		}
+		if runes := []rune(word); len(runes) > MaxTokenLen {
+			word = string(runes[:MaxTokenLen])
+		}
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		seen[word] = struct{}{}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// Prefix returns the index partition of the token.
+func Prefix(token string) string {
+	if runes := []rune(token); len(runes) > PrefixLen {
+		return string(runes[:PrefixLen])
+	}
+	return token
+}
+
+// Relevance scores a document token matched by the query token,
+// prefix matches are scored by the share of the token covered by the query.
+func Relevance(query, token string, weight int) float64 {
+	if query == token {
+		return float64(weight)
+	}
+	covered := float64(utf8.RuneCountInString(query)) / float64(utf8.RuneCountInString(token))
+	return float64(weight) * covered * prefixPenalty
+}
+
+// Rank combines text relevance with the dictionary rating, the rating boost grows logarithmically.
+func Rank(relevance float64, rating int) float64 {
+	if rating < 0 {
+		rating = 0
+	}
+	return relevance * (1 + ratingBoost*math.Log1p(float64(rating)))
+}
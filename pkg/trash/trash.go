@@ -0,0 +1,75 @@
+// Package trash implements soft deletion of DynamoDB items.
+//
+// Trashed items keep their primary key, lose hash keys of listing indexes and get trash attributes:
+// is_deleted and deleted_at are keys of the sparse DeletedByDateIndex,
+// ttl lets DynamoDB purge the item when Retention is over.
+package trash
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Retention is how long trashed items can be restored.
+const Retention = 30 * 24 * time.Hour
+
+// IndexName is the sparse index of trashed items ordered by deletion time.
+const IndexName = "DeletedByDateIndex"
+
+const (
+	AttrIsDeleted = "is_deleted"
+	AttrDeletedAt = "deleted_at"
+	AttrDeletedBy = "deleted_by"
+	AttrTTL       = "ttl"
+)
+
+var attributes = []string{AttrIsDeleted, AttrDeletedAt, AttrDeletedBy, AttrTTL}
+
+// Delete returns update moving the item to trash, listingKeys are removed to drop the item from listing indexes.
+func Delete(actor string, now time.Time, listingKeys ...string) expression.UpdateBuilder {
+	update := expression.
+		Set(expression.Name(AttrIsDeleted), expression.Value(1)).
+		Set(expression.Name(AttrDeletedAt), expression.Value(now.Unix())).
+		Set(expression.Name(AttrDeletedBy), expression.Value(actor)).
+		Set(expression.Name(AttrTTL), expression.Value(PurgeAt(now.Unix())))
+
+	for _, key := range listingKeys {
+		update = update.Remove(expression.Name(key))
+	}
+	return update
+}
+
+// Restore returns update removing trash attributes, callers set listing keys back.
+func Restore() expression.UpdateBuilder {
+	var update expression.UpdateBuilder
+	for _, attr := range attributes {
+		update = update.Remove(expression.Name(attr))
+	}
+	return update
+}
+
+// Deleted matches items in trash.
+func Deleted() expression.ConditionBuilder {
+	return expression.AttributeExists(expression.Name(AttrDeletedAt))
+}
+
+// NotDeleted matches items which are not in trash, it also matches missing items.
+func NotDeleted() expression.ConditionBuilder {
+	return expression.AttributeNotExists(expression.Name(AttrDeletedAt))
+}
+
+// PurgeAt returns unix time when the item deleted at deletedAt expires.
+func PurgeAt(deletedAt int64) int64 {
+	return deletedAt + int64(Retention/time.Second)
+}
+
+// Omit removes trash attributes from the marshalled item before Put,
+// generated schema items marshal them as zero values which would put the item into the trash index.
+func Omit(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	for _, attr := range attributes {
+		delete(item, attr)
+	}
+	return item
+}
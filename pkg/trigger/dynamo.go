@@ -0,0 +1,72 @@
+package trigger
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// UnmarshalStreamImage decodes DynamoDB stream image into out using "dynamodbav" tags,
+// so the generated SchemaItem types can be used for stream records too.
+func UnmarshalStreamImage(image map[string]events.DynamoDBAttributeValue, out any) error {
+	item, err := streamImageToItem(image)
+	if err != nil {
+		return err
+	}
+	if err = attributevalue.UnmarshalMap(item, out); err != nil {
+		return errors.Wrap(err, "failed to unmarshal stream image")
+	}
+	return nil
+}
+
+func streamImageToItem(image map[string]events.DynamoDBAttributeValue) (map[string]types.AttributeValue, error) {
+	item := make(map[string]types.AttributeValue, len(image))
+	for name, value := range image {
+		av, err := streamAttributeValue(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "attribute '%s'", name)
+		}
+		item[name] = av
+	}
+	return item, nil
+}
+
+func streamAttributeValue(value events.DynamoDBAttributeValue) (types.AttributeValue, error) {
+	switch value.DataType() {
+	case events.DataTypeString:
+		return &types.AttributeValueMemberS{Value: value.String()}, nil
+	case events.DataTypeNumber:
+		return &types.AttributeValueMemberN{Value: value.Number()}, nil
+	case events.DataTypeBinary:
+		return &types.AttributeValueMemberB{Value: value.Binary()}, nil
+	case events.DataTypeBoolean:
+		return &types.AttributeValueMemberBOOL{Value: value.Boolean()}, nil
+	case events.DataTypeNull:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case events.DataTypeStringSet:
+		return &types.AttributeValueMemberSS{Value: value.StringSet()}, nil
+	case events.DataTypeNumberSet:
+		return &types.AttributeValueMemberNS{Value: value.NumberSet()}, nil
+	case events.DataTypeBinarySet:
+		return &types.AttributeValueMemberBS{Value: value.BinarySet()}, nil
+	case events.DataTypeList:
+		list := make([]types.AttributeValue, 0, len(value.List()))
+		for _, v := range value.List() {
+			av, err := streamAttributeValue(v)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, av)
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case events.DataTypeMap:
+		m, err := streamImageToItem(value.Map())
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	default:
+		return nil, errors.Errorf("unsupported data type %d", value.DataType())
+	}
+}
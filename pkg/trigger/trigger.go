@@ -27,6 +27,8 @@ type HandleFunc func(context.Context, zerolog.Logger, json.RawMessage) error
 
 // Config contains trigger configuration.
 type Config struct {
+	// MaxWorkers bounds records processed at once, one worker processes records in event order
+	// and stops at the first failed record, so later changes are never applied before it.
 	MaxWorkers int
 }
 
@@ -50,7 +52,8 @@ func NewLambda(cfg Config, handler HandleFunc) *Trigger {
 }
 
 // Handle processes AWS Lambda events by applying the handler function to each record.
-// It supports various event types such as DynamoDB and SQS events, and processes records in parallel.
+// It supports various event types such as DynamoDB and SQS events, and processes records in parallel
+// unless a single worker is configured.
 func (t *Trigger) Handle(ctx context.Context, event map[string]json.RawMessage) error {
 	records, err := t.getRecords(event)
 	if err != nil {
@@ -63,6 +66,9 @@ func (t *Trigger) Handle(ctx context.Context, event map[string]json.RawMessage)
 		return nil
 	}
 	maxWorkers := t.getMaxWorkers(len(records))
+	if maxWorkers == 1 {
+		return t.processRecordsInOrder(ctx, records)
+	}
 	return t.processRecords(ctx, records, maxWorkers)
 }
 
@@ -115,6 +121,23 @@ func (t *Trigger) processRecords(ctx context.Context, records []json.RawMessage,
 	return t.collectErrors(errChan)
 }
 
+// processRecordsInOrder handles records one by one in event order.
+func (t *Trigger) processRecordsInOrder(ctx context.Context, records []json.RawMessage) error {
+	t.log.Info().
+		Int("total_records", len(records)).
+		Msg("Starting ordered records processing")
+
+	for i, record := range records {
+		recordLogger := t.log.With().Int("record_number", i+1).Logger()
+
+		if err := t.handler(ctx, recordLogger, record); err != nil {
+			t.log.Error().Err(err).Int("skipped_records", len(records)-i-1).Msg("Error processing record")
+			return fmt.Errorf("%w: record %d processing failed: %w", errProcessingFailed, i+1, err)
+		}
+	}
+	return nil
+}
+
 // collectErrors gathers all errors from the error channel.
 func (t *Trigger) collectErrors(errChan <-chan error) error {
 	var errs []error
@@ -0,0 +1,53 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHandleInOrder(t *testing.T) {
+	errRecord := errors.New("record failed")
+	event := map[string]json.RawMessage{recordsKey: json.RawMessage(`[1,2,3,4,5,6,7,8]`)}
+
+	tests := []struct {
+		name    string
+		failOn  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "all records in order", want: []string{"1", "2", "3", "4", "5", "6", "7", "8"}},
+		{name: "stops at failed record", failOn: "3", want: []string{"1", "2", "3"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			trigger := NewLambda(Config{MaxWorkers: 1}, func(_ context.Context, _ zerolog.Logger, record json.RawMessage) error {
+				got = append(got, string(record))
+				if string(record) == tt.failOn {
+					return errRecord
+				}
+				return nil
+			})
+
+			err := trigger.Handle(context.Background(), event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Handle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errRecord) {
+				t.Errorf("Handle() error = %v, want %v", err, errRecord)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("handled %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("handled %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
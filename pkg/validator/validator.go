@@ -1,7 +1,9 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"unicode"
 
@@ -12,8 +14,16 @@ type Validator struct {
 	validate *validator.Validate
 }
 
+// FieldError describes a single failed validation rule.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
 func New() *Validator {
 	v := validator.New()
+	v.RegisterTagNameFunc(jsonFieldName)
 	registerCustomTags(v)
 
 	return &Validator{validate: v}
@@ -38,6 +48,39 @@ func (v *Validator) StructErrorToString(err error) string {
 	return err.Error()
 }
 
+// ErrorFields converts validation errors into a list of failed fields, ok is false for other errors.
+func ErrorFields(err error) ([]FieldError, bool) {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+
+	fields := make([]FieldError, 0, len(errs))
+	for _, e := range errs {
+		field := e.Field()
+		if _, path, ok := strings.Cut(e.Namespace(), "."); ok {
+			field = path
+		}
+		fields = append(fields, FieldError{
+			Field:   field,
+			Rule:    e.Tag(),
+			Message: fmt.Sprintf("Field '%s' failed validation '%s'", field, e.Tag()),
+		})
+	}
+	return fields, true
+}
+
+// jsonFieldName reports fields by their json (or query "form") name instead of Go name.
+func jsonFieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
 func registerCustomTags(v *validator.Validate) {
 	v.RegisterValidation("base_str", func(fl validator.FieldLevel) bool {
 		validChars := ".-_:"
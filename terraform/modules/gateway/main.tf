@@ -11,6 +11,8 @@ locals {
 
     api_subcategories = var.invoke_lambdas_arns["api-subcategories"].arn
     api_dictionaries  = var.invoke_lambdas_arns["api-dictionaries"].arn
+    api_devices       = var.invoke_lambdas_arns["api-devices"].arn
+    api_auth          = var.invoke_lambdas_arns["api-auth"].arn
     api_reports       = var.invoke_lambdas_arns["api-reports"].arn
     api_levels        = var.invoke_lambdas_arns["api-levels"].arn
     api_urls          = var.invoke_lambdas_arns["api-urls"].arn
@@ -0,0 +1,19 @@
+resource "aws_kms_key" "this" {
+  description             = "${var.project}-${var.key_name}"
+  deletion_window_in_days = var.deletion_window_in_days
+  enable_key_rotation     = true
+
+  tags = merge(
+    var.shared_tags,
+    {
+      "TF"      = "true",
+      "Project" = var.project,
+      "Github"  = "github.com/Mad-Pixels/applingo-api",
+    }
+  )
+}
+
+resource "aws_kms_alias" "this" {
+  name          = "alias/${var.project}-${var.key_name}"
+  target_key_id = aws_kms_key.this.key_id
+}
@@ -0,0 +1,20 @@
+variable "project" {
+  description = "Project name"
+  type        = string
+}
+
+variable "key_name" {
+  description = "Name of the KMS key, used in its description and alias"
+  type        = string
+}
+
+variable "deletion_window_in_days" {
+  description = "Days before the scheduled deletion of the key, data encrypted with a deleted key cannot be decrypted"
+  type        = number
+  default     = 30
+}
+
+variable "shared_tags" {
+  description = "Tags to add to all resources"
+  default     = {}
+}
@@ -7,6 +7,34 @@ locals {
     file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_dictionary_table.json")
   )
 
+  dictionary_search_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_dictionary_search_table.json")
+  )
+
+  dictionary_rating_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_dictionary_rating_table.json")
+  )
+
+  dictionary_version_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_dictionary_version_table.json")
+  )
+
+  device_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_device_table.json")
+  )
+
+  device_nonce_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_device_nonce_table.json")
+  )
+
+  user_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_user_table.json")
+  )
+
+  user_token_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_user_token_table.json")
+  )
+
   subcategory_dynamo_schema = jsondecode(
     file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_subcategory_table.json")
   )
@@ -47,6 +47,96 @@ module "dynamo-dictionary-table" {
   attributes           = local.dictionary_dynamo_schema.attributes
   secondary_index_list = local.dictionary_dynamo_schema.secondary_indexes
   stream_enabled       = true
+  stream_type          = "NEW_AND_OLD_IMAGES"
+
+  // trashed dictionaries are purged by TTL, REMOVE events clean up their files.
+  ttl_enabled = true
+}
+
+module "dynamo-dictionary-search-table" {
+  source = "../../modules/dynamo"
+
+  project        = local.project
+  table_name     = local.dictionary_search_dynamo_schema.table_name
+  hash_key       = local.dictionary_search_dynamo_schema.hash_key
+  range_key      = local.dictionary_search_dynamo_schema.range_key
+  attributes     = local.dictionary_search_dynamo_schema.attributes
+  stream_enabled = false
+}
+
+module "dynamo-dictionary-rating-table" {
+  source = "../../modules/dynamo"
+
+  project        = local.project
+  table_name     = local.dictionary_rating_dynamo_schema.table_name
+  hash_key       = local.dictionary_rating_dynamo_schema.hash_key
+  range_key      = local.dictionary_rating_dynamo_schema.range_key
+  attributes     = local.dictionary_rating_dynamo_schema.attributes
+  stream_enabled = false
+}
+
+module "dynamo-dictionary-version-table" {
+  source = "../../modules/dynamo"
+
+  project        = local.project
+  table_name     = local.dictionary_version_dynamo_schema.table_name
+  hash_key       = local.dictionary_version_dynamo_schema.hash_key
+  range_key      = local.dictionary_version_dynamo_schema.range_key
+  attributes     = local.dictionary_version_dynamo_schema.attributes
+  stream_enabled = false
+}
+
+module "dynamo-device-table" {
+  source = "../../modules/dynamo"
+
+  project        = local.project
+  table_name     = local.device_dynamo_schema.table_name
+  hash_key       = local.device_dynamo_schema.hash_key
+  attributes     = local.device_dynamo_schema.attributes
+  stream_enabled = false
+}
+
+module "dynamo-device-nonce-table" {
+  source = "../../modules/dynamo"
+
+  project        = local.project
+  table_name     = local.device_nonce_dynamo_schema.table_name
+  hash_key       = local.device_nonce_dynamo_schema.hash_key
+  attributes     = local.device_nonce_dynamo_schema.attributes
+  stream_enabled = false
+
+  // nonces are kept only while their request timestamp is valid.
+  ttl_enabled = true
+}
+
+module "kms-device-secret-key" {
+  source = "../../modules/kms"
+
+  project  = local.project
+  key_name = "device-secret"
+}
+
+module "dynamo-user-table" {
+  source = "../../modules/dynamo"
+
+  project        = local.project
+  table_name     = local.user_dynamo_schema.table_name
+  hash_key       = local.user_dynamo_schema.hash_key
+  attributes     = local.user_dynamo_schema.attributes
+  stream_enabled = false
+}
+
+module "dynamo-user-token-table" {
+  source = "../../modules/dynamo"
+
+  project        = local.project
+  table_name     = local.user_token_dynamo_schema.table_name
+  hash_key       = local.user_token_dynamo_schema.hash_key
+  attributes     = local.user_token_dynamo_schema.attributes
+  stream_enabled = false
+
+  // sessions are removed once their refresh token expires.
+  ttl_enabled = true
 }
 
 module "dynamo-subcategory-table" {
@@ -58,6 +148,7 @@ module "dynamo-subcategory-table" {
   attributes           = local.subcategory_dynamo_schema.attributes
   secondary_index_list = local.subcategory_dynamo_schema.secondary_indexes
   stream_enabled       = false
+  ttl_enabled          = true
 }
 
 module "dynamo-level-table" {
@@ -75,4 +166,12 @@ module "dictionary_put_csv_queue" {
 
   project    = local.project
   queue_name = "put"
+}
+
+module "dictionary_delete_csv_queue" {
+  source = "../../modules/sqs"
+
+  project       = local.project
+  queue_name    = "delete"
+  delay_seconds = 0
 }
\ No newline at end of file
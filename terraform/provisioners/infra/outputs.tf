@@ -38,6 +38,66 @@ output "dynamo-dictionary-stream_arn" {
   value = module.dynamo-dictionary-table.stream_arn
 }
 
+output "dynamo-dictionary-search-table_name" {
+  value = module.dynamo-dictionary-search-table.table_name
+}
+
+output "dynamo-dictionary-search-table_arn" {
+  value = module.dynamo-dictionary-search-table.table_arn
+}
+
+output "dynamo-dictionary-rating-table_name" {
+  value = module.dynamo-dictionary-rating-table.table_name
+}
+
+output "dynamo-dictionary-rating-table_arn" {
+  value = module.dynamo-dictionary-rating-table.table_arn
+}
+
+output "dynamo-dictionary-version-table_name" {
+  value = module.dynamo-dictionary-version-table.table_name
+}
+
+output "dynamo-dictionary-version-table_arn" {
+  value = module.dynamo-dictionary-version-table.table_arn
+}
+
+output "dynamo-device-table_name" {
+  value = module.dynamo-device-table.table_name
+}
+
+output "dynamo-device-table_arn" {
+  value = module.dynamo-device-table.table_arn
+}
+
+output "dynamo-device-nonce-table_name" {
+  value = module.dynamo-device-nonce-table.table_name
+}
+
+output "dynamo-device-nonce-table_arn" {
+  value = module.dynamo-device-nonce-table.table_arn
+}
+
+output "kms-device-secret-key_arn" {
+  value = module.kms-device-secret-key.key_arn
+}
+
+output "dynamo-user-table_name" {
+  value = module.dynamo-user-table.table_name
+}
+
+output "dynamo-user-table_arn" {
+  value = module.dynamo-user-table.table_arn
+}
+
+output "dynamo-user-token-table_name" {
+  value = module.dynamo-user-token-table.table_name
+}
+
+output "dynamo-user-token-table_arn" {
+  value = module.dynamo-user-token-table.table_arn
+}
+
 output "dynamo-subcategory-table_name" {
   value = module.dynamo-subcategory-table.table_name
 }
@@ -69,3 +129,19 @@ output "sqs-put-csv-queue_url" {
 output "sqs-put-csv-queue_arn" {
   value = module.dictionary_put_csv_queue.queue_arn
 }
+
+output "sqs-delete-csv-dead-letter-queue_url" {
+  value = module.dictionary_delete_csv_queue.dead_letter_queue_url
+}
+
+output "sqs-delete-csv-dead-letter-queue_arn" {
+  value = module.dictionary_delete_csv_queue.dead_letter_queue_arn
+}
+
+output "sqs-delete-csv-queue_url" {
+  value = module.dictionary_delete_csv_queue.queue_url
+}
+
+output "sqs-delete-csv-queue_arn" {
+  value = module.dictionary_delete_csv_queue.queue_arn
+}
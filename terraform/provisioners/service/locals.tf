@@ -22,19 +22,34 @@ locals {
 
   // template variables which use in ./infra/config.json of each lambda.
   template_vars = {
-    var_jwt_secret              = var.jwt_secret
-    var_device_api_token        = var.device_api_token
-    log_errors_bucket_name      = data.terraform_remote_state.infra.outputs.s3-errors-bucket_name
-    dictionary_bucket_name      = data.terraform_remote_state.infra.outputs.s3-dictionary-bucket_name
-    processing_bucket_name      = data.terraform_remote_state.infra.outputs.s3-processing-bucket_name
-    log_errors_bucket_arn       = data.terraform_remote_state.infra.outputs.s3-errors-bucket_arn
-    dictionary_bucket_arn       = data.terraform_remote_state.infra.outputs.s3-dictionary-bucket_arn
-    processing_bucket_arn       = data.terraform_remote_state.infra.outputs.s3-processing-bucket_arn
-    dictionary_table_arn        = data.terraform_remote_state.infra.outputs.dynamo-dictionary-table_arn
-    dictionary_table_stream_arn = data.terraform_remote_state.infra.outputs.dynamo-dictionary-stream_arn
-    subcategory_table_arn       = data.terraform_remote_state.infra.outputs.dynamo-subcategory-table_arn
-    level_table_arn             = data.terraform_remote_state.infra.outputs.dynamo-level-table_arn
-    put_csv_sqs_queue_url       = data.terraform_remote_state.infra.outputs.sqs-put-csv-queue_url
-    put_csv_sqs_queue_arn       = data.terraform_remote_state.infra.outputs.sqs-put-csv-queue_arn
+    var_jwt_secret               = var.jwt_hs256_enabled ? var.jwt_secret : ""
+    var_jwt_signing_key          = var.jwt_signing_key
+    var_jwt_signing_key_id       = var.jwt_signing_key_id
+    var_jwt_jwks                 = var.jwt_jwks
+    var_device_api_token         = var.device_api_token
+    var_device_legacy_until      = var.device_legacy_until
+    var_pagination_secret        = var.pagination_secret
+    log_errors_bucket_name       = data.terraform_remote_state.infra.outputs.s3-errors-bucket_name
+    dictionary_bucket_name       = data.terraform_remote_state.infra.outputs.s3-dictionary-bucket_name
+    processing_bucket_name       = data.terraform_remote_state.infra.outputs.s3-processing-bucket_name
+    log_errors_bucket_arn        = data.terraform_remote_state.infra.outputs.s3-errors-bucket_arn
+    dictionary_bucket_arn        = data.terraform_remote_state.infra.outputs.s3-dictionary-bucket_arn
+    processing_bucket_arn        = data.terraform_remote_state.infra.outputs.s3-processing-bucket_arn
+    dictionary_table_arn         = data.terraform_remote_state.infra.outputs.dynamo-dictionary-table_arn
+    dictionary_table_stream_arn  = data.terraform_remote_state.infra.outputs.dynamo-dictionary-stream_arn
+    dictionary_search_table_arn  = data.terraform_remote_state.infra.outputs.dynamo-dictionary-search-table_arn
+    dictionary_rating_table_arn  = data.terraform_remote_state.infra.outputs.dynamo-dictionary-rating-table_arn
+    dictionary_version_table_arn = data.terraform_remote_state.infra.outputs.dynamo-dictionary-version-table_arn
+    subcategory_table_arn        = data.terraform_remote_state.infra.outputs.dynamo-subcategory-table_arn
+    level_table_arn              = data.terraform_remote_state.infra.outputs.dynamo-level-table_arn
+    device_table_arn             = data.terraform_remote_state.infra.outputs.dynamo-device-table_arn
+    device_nonce_table_arn       = data.terraform_remote_state.infra.outputs.dynamo-device-nonce-table_arn
+    device_secret_key_arn        = data.terraform_remote_state.infra.outputs.kms-device-secret-key_arn
+    user_table_arn               = data.terraform_remote_state.infra.outputs.dynamo-user-table_arn
+    user_token_table_arn         = data.terraform_remote_state.infra.outputs.dynamo-user-token-table_arn
+    put_csv_sqs_queue_url        = data.terraform_remote_state.infra.outputs.sqs-put-csv-queue_url
+    put_csv_sqs_queue_arn        = data.terraform_remote_state.infra.outputs.sqs-put-csv-queue_arn
+    delete_csv_sqs_queue_url     = data.terraform_remote_state.infra.outputs.sqs-delete-csv-queue_url
+    delete_csv_sqs_queue_arn     = data.terraform_remote_state.infra.outputs.sqs-delete-csv-queue_arn
   }
 }
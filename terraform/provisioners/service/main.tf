@@ -36,6 +36,17 @@ resource "aws_lambda_event_source_mapping" "dynamo-queue" {
   depends_on = [module.lambda_functions]
 }
 
+resource "aws_lambda_event_source_mapping" "dynamo-search-index" {
+  event_source_arn               = local.template_vars.dictionary_table_stream_arn
+  function_name                  = module.lambda_functions["trigger-dynamo-to-search-index"].function_arn
+  starting_position              = "LATEST"
+  maximum_retry_attempts         = 3
+  maximum_record_age_in_seconds  = 3600
+  bisect_batch_on_function_error = true
+
+  depends_on = [module.lambda_functions]
+}
+
 resource "aws_lambda_event_source_mapping" "queue-put-csv" {
   event_source_arn = local.template_vars.put_csv_sqs_queue_arn
   function_name    = module.lambda_functions["trigger-sqs-to-job-put-csv"].function_arn
@@ -43,6 +54,13 @@ resource "aws_lambda_event_source_mapping" "queue-put-csv" {
   depends_on = [module.lambda_functions]
 }
 
+resource "aws_lambda_event_source_mapping" "queue-delete-csv" {
+  event_source_arn = local.template_vars.delete_csv_sqs_queue_arn
+  function_name    = module.lambda_functions["trigger-sqs-to-job-delete-csv"].function_arn
+
+  depends_on = [module.lambda_functions]
+}
+
 module "gateway" {
   source = "../../modules/gateway"
 
@@ -25,7 +25,53 @@ variable "device_api_token" {
   type        = string
 }
 
+variable "device_legacy_until" {
+  description = "RFC 3339 end of the migration window for legacy device signatures and the shared token, a past time closes it"
+  type        = string
+
+  validation {
+    condition     = can(formatdate("YYYY", var.device_legacy_until))
+    error_message = "The device_legacy_until must be RFC 3339 time, e.g. 2025-06-01T00:00:00Z."
+  }
+}
+
 variable "jwt_secret" {
   description = "Auth JWT secret which use for lambda request validate from external"
   type        = string
+  default     = ""
+}
+
+variable "jwt_hs256_enabled" {
+  description = "Deploy jwt_secret, so HS256 user tokens are signed and accepted. Disable after rotation to jwt_signing_key once HS256 tokens expired"
+  type        = bool
+  default     = true
+
+  validation {
+    condition     = var.jwt_hs256_enabled ? var.jwt_secret != "" : var.jwt_signing_key != "" && var.jwt_jwks != ""
+    error_message = "The jwt_secret is required while jwt_hs256_enabled, otherwise jwt_signing_key and jwt_jwks are required."
+  }
+}
+
+variable "jwt_signing_key" {
+  description = "PEM private key (RSA, EC P-256 or Ed25519) which signs user tokens, empty signs them with jwt_secret"
+  type        = string
+  default     = ""
+  sensitive   = true
+}
+
+variable "jwt_signing_key_id" {
+  description = "Key id of jwt_signing_key, sent as 'kid' token header"
+  type        = string
+  default     = ""
+}
+
+variable "jwt_jwks" {
+  description = "JWKS JSON, URL or file path with public keys which verify user tokens, keep rotated keys until their tokens expire"
+  type        = string
+  default     = ""
+}
+
+variable "pagination_secret" {
+  description = "Secret which use for signing pagination cursors in list endpoints"
+  type        = string
 }
\ No newline at end of file